@@ -5,19 +5,27 @@ import (
 	"fmt"
 	"html"
 	"os"
-	"regexp"
 	"sort"
+	"strings"
+	"unicode/utf8"
 
-	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/text"
 )
 
-// Regex to find "word:rubi". It's a package-level variable to avoid recompilation.
-// Matches one or more word characters (alphanumeric and underscore).
-// Note: \w in Go's regex typically includes alphanumeric and underscore.
-// For Japanese characters, a different regex might be needed (e.g., Unicode categories).
-var rubiRegex = regexp.MustCompile(`(\w+):rubi\b`)
+// rubiSuffix is the marker that requests a ruby annotation for the preceding word in manual mode.
+const rubiSuffix = ":rubi"
+
+// rubiMarker is a "word:rubi" or "{some words}:rubi" marker found in a text node.
+// Offsets are relative to the text node.
+type rubiMarker struct {
+	Start     int // Start of the whole marker, including an opening brace
+	WordStart int // Start of the marked word
+	WordEnd   int // End of the marked word
+	End       int // End of the whole marker, including an inline reading
+	// Reading is the inline reading given as "word:rubi(reading)", or empty if none was given.
+	Reading string
+}
 
 // Patch represents a single change to be applied to the content.
 type Patch struct {
@@ -63,26 +71,231 @@ func ApplyPatches(original []byte, patches []Patch) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// Processor converts Markdown documents using a loaded term dictionary.
+// The scan-mode matcher is built once in NewProcessor and reused for every
+// document, so a single Processor should be shared across files.
+// A Processor is safe for concurrent use.
+type Processor struct {
+	termMap map[string]Term
+	index   *termIndex
+	matcher *Matcher
+}
+
+// NewProcessor creates a Processor for the given term dictionary.
+func NewProcessor(termMap map[string]Term) *Processor {
+	return &Processor{
+		termMap: termMap,
+		index:   newTermIndex(termMap),
+		matcher: NewMatcher(termMap),
+	}
+}
+
+// Options controls how a Processor converts a document.
+type Options struct {
+	DryRun         bool     // Log the patches to stderr instead of applying them
+	Scan           bool     // Convert every dictionary term instead of only ":rubi" markers
+	FirstOnly      bool     // In scan mode, convert only the first occurrence of each term
+	FirstOnlyScope string   // Where FirstOnly starts over, as accepted by --first-only-scope (the whole document if empty)
+	Quiet          bool     // Do not write warnings or the dry-run log to stderr
+	Refresh        bool     // Update the reading of existing ruby markup that no longer matches the dictionary
+	Renderer       string   // Name of the Renderer used for the annotations, defaultRenderer if empty
+	Ref            string   // How to show the reading source: RefNone (default), RefTitle, RefLink or RefFootnote
+	Alternates     string   // How to show alternate readings: AlternatesNone (default), AlternatesTitle or AlternatesGlossary
+	Glossary       bool     // Add a table of the dictionary terms in the document, see renderGlossarySection
+	ExcludeNodes   []string // Node kinds, as accepted by --exclude-nodes, whose text is never converted (code and HTML always are)
+	OnlyTags       []string // In scan mode, convert only terms with one of these tags (all terms if empty)
+	ExcludeTags    []string // In scan mode, never convert terms with any of these tags
+}
+
+// Conversion modes reported in Occurrence.Mode.
+const (
+	ModeManual  = "manual"
+	ModeScan    = "scan"
+	ModeStrip   = "strip"
+	ModeRefresh = "refresh"
+)
+
+// Reasons reported in Occurrence.Skip when no ruby was generated for a term.
+const (
+	SkipUnknownTerm = "unknown term"
+	SkipFirstOnly   = "first-only"
+	SkipDisabled    = "disabled" // Suppressed by a rubi-disable, rubi-disable-next-line or rubi-ignore directive
+)
+
+// Occurrence records a term found while processing a document.
+type Occurrence struct {
+	Start  int    // Byte offset of the match (or the whole marker in manual mode) in the document
+	End    int    // Byte offset just past the match
+	Term   string // The word as written in the document
+	Yomi   string // Reading used for the ruby, empty for unknown terms
+	Ref    string // Reading source from the dictionary, if any
+	Source string // Dictionary file the term was found in, if any
+	Mode   string // ModeManual, ModeScan, ModeRefresh or ModeStrip
+	Skip   string // Why no ruby was generated, or empty if the term was converted
+}
+
+// Result is the outcome of processing a single document.
+type Result struct {
+	Content     []byte       // Converted document, or the original content in dry-run mode
+	Patches     []Patch      // Changes made to the document (or that would be made in dry-run mode)
+	Occurrences []Occurrence // Every term found, in document order, including ones that were not converted
+}
+
 // ProcessMarkdown parses the given Markdown content and traverses its AST.
 // In manual mode, it finds words marked with the ":rubi" suffix and converts them to HTML ruby tags.
 // In scan mode, it automatically detects all dictionary terms and converts them to HTML ruby tags.
 // The firstOnly parameter (only valid in scan mode) limits conversion to the first occurrence of each term.
 // All conversions are based on the provided term dictionary.
+// Ruby markup generated by an earlier run is left as is, so the conversion can be repeated safely.
+// Callers processing more than one document should create a Processor once and call Process instead.
 func ProcessMarkdown(content []byte, dryRun bool, scan bool, firstOnly bool, termMap map[string]Term) ([]byte, error) {
-	md := goldmark.New()
-	document := md.Parser().Parse(text.NewReader(content))
+	result, err := NewProcessor(termMap).Process(content, Options{DryRun: dryRun, Scan: scan, FirstOnly: firstOnly})
+	if err != nil {
+		return nil, err
+	}
+	return result.Content, nil
+}
+
+// Process converts a single Markdown document. See ProcessMarkdown for the conversion rules.
+func (p *Processor) Process(content []byte, opts Options) (*Result, error) {
+	logf := func(format string, args ...any) {
+		if !opts.Quiet {
+			fmt.Fprintf(os.Stderr, format, args...)
+		}
+	}
+
+	renderer, err := newRenderer(opts.Renderer, opts.Ref, opts.Alternates)
+	if err != nil {
+		return nil, err
+	}
+	renderer.Begin(content)
+
+	excluded, err := newNodeFilter(opts.ExcludeNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Front matter is never converted, but may override the options for this document
+	source := content
+	var excludedTerms []string
+	if fm := findFrontMatter(content); fm != nil {
+		settings, err := fm.settings()
+		if err != nil {
+			return nil, err
+		}
+		if settings.Scan != nil {
+			opts.Scan = *settings.Scan
+		}
+		if settings.FirstOnly != nil {
+			opts.FirstOnly = *settings.FirstOnly
+		}
+		if settings.FirstOnlyScope != nil {
+			opts.FirstOnlyScope = *settings.FirstOnlyScope
+		}
+		if settings.OnlyTags != nil {
+			opts.OnlyTags = settings.OnlyTags
+		}
+		if settings.ExcludeTags != nil {
+			opts.ExcludeTags = settings.ExcludeTags
+		}
+		excludedTerms = settings.Exclude
+		source = fm.mask(content)
+	}
+	tags := newTagFilter(opts.OnlyTags, opts.ExcludeTags)
+
+	document := newMarkdown().Parser().Parse(text.NewReader(source))
 
 	var patches []Patch
-	// To track terms for firstOnly. Note: this tracking is case-sensitive based on matched word.
-	// If case-insensitivity is desired, terms should be normalized (e.g., to lowercase) before tracking.
+	var occurrences []Occurrence
+	// To track terms for firstOnly, by dictionary entry so that aliases and other cases count as the same term
 	processedTerms := make(map[string]bool)
+	scope, err := parseFirstOnlyScope(opts.FirstOnlyScope)
+	if err != nil {
+		return nil, err
+	}
+	scopes := &scopeTracker{scope: scope}
+
+	// Dictionary terms annotated in the document (including existing ruby markup), in order of appearance
+	var annotated []Term
+	annotatedTerms := make(map[string]bool)
+	annotate := func(term Term) {
+		if !annotatedTerms[term.Term] {
+			annotatedTerms[term.Term] = true
+			annotated = append(annotated, term)
+		}
+	}
+
+	// Dictionary terms that appear anywhere in the document, in order of appearance, for the glossary
+	var glossary []Term
+	glossaryTerms := make(map[string]bool)
+	addToGlossary := func(term Term) {
+		if opts.Glossary && !glossaryTerms[term.Term] {
+			glossaryTerms[term.Term] = true
+			glossary = append(glossary, term)
+		}
+	}
+
+	// Sections generated by rubi are rewritten as a whole, never converted
+	comments := findCommentLines(document, content)
+	var generated [][2]int
+	for _, name := range generatedSections {
+		if start, end, ok := findSection(comments, content, name); ok {
+			generated = append(generated, [2]int{start, end})
+		}
+	}
+	inGenerated := func(offset int) bool {
+		for _, r := range generated {
+			if offset >= r[0] && offset < r[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Directives such as "<!-- rubi-disable -->" suppress conversion in parts of the document
+	suppress := findSuppressions(document, content)
+	suppress.ignore(0, excludedTerms)
+
+	// Existing ruby markup is never converted again. Its term counts as already
+	// converted for firstOnly, and its reading is updated if opts.Refresh is set.
+	spans := findRubySpans(document, content)
+	elements := findRubyElements(document, content)
+	lastSpan := -1
+	annotatedEnd := 0 // End of the last markup of a non-HTML renderer found in scan mode
+	handleSpan := func(span rubySpan) {
+		word := html.UnescapeString(span.Word)
+		term, found := p.index.lookup(word)
+		if !found {
+			return
+		}
+		processedTerms[term.Term] = true
+		annotate(term)
+		addToGlossary(term)
+		if !opts.Refresh || html.UnescapeString(span.Reading) == term.Yomi || suppress.suppressed(span.Start, word, term.Term) {
+			return
+		}
+		newText := renderer.Render(html.UnescapeString(span.Word), term.Yomi, term.Ref, term.Alternates())
+		patches = append(patches, Patch{Start: span.Start, End: span.End, NewText: []byte(newText)})
+		occurrences = append(occurrences, Occurrence{Start: span.Start, End: span.End, Term: word, Yomi: term.Yomi, Ref: term.Ref, Source: term.Source, Mode: ModeRefresh})
+		if opts.DryRun {
+			logf("GENERATING PATCH (Refresh): Found '%s' read as '%s', replace with '%s' (Offset: %d-%d)\n", word, html.UnescapeString(span.Reading), newText, span.Start, span.End)
+		}
+	}
 
 	walker := func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
 		}
 
+		// Start tracking first occurrences over at every new scope, e.g. every section
+		if scopes.starts(n) {
+			processedTerms = make(map[string]bool)
+		}
+
 		// Implement exclusion logic
+		if excluded(n) {
+			return ast.WalkSkipChildren, nil
+		}
 		switch n.Kind() {
 		case ast.KindCodeBlock, ast.KindFencedCodeBlock, ast.KindHTMLBlock, ast.KindRawHTML, ast.KindCodeSpan:
 			return ast.WalkSkipChildren, nil
@@ -90,73 +303,118 @@ func ProcessMarkdown(content []byte, dryRun bool, scan bool, firstOnly bool, ter
 			return ast.WalkContinue, nil
 		case ast.KindText:
 			segment := n.(*ast.Text).Segment
+			if inGenerated(segment.Start) {
+				return ast.WalkContinue, nil
+			}
+			if i := rubySpanIndex(spans, segment.Start); i >= 0 {
+				// The base text of existing ruby markup
+				if i != lastSpan {
+					lastSpan = i
+					handleSpan(spans[i])
+				}
+				return ast.WalkContinue, nil
+			}
+			if rubySpanIndex(elements, segment.Start) >= 0 {
+				// Text of ruby markup written by hand or by another tool
+				return ast.WalkContinue, nil
+			}
 			textBytes := segment.Value(content)
 			textStr := string(textBytes)
 
-			if scan {
-				// Scan mode: find any dictionary term
-				for termStr, termData := range termMap {
-					// Use a word boundary to prevent partial matches (e.g., "go" matching "golang")
-					// This regex finds all occurrences of the term in the text node
-					// We use a non-capturing group for the word boundary \b to avoid issues with FindAllStringSubmatchIndex
-					// Note: \w in Go's regex typically includes alphanumeric and underscore.
-					// For Japanese characters, a different regex might be needed (e.g., Unicode categories).
-					scanTermRegex := regexp.MustCompile(fmt.Sprintf(`\b(%s)\b`, regexp.QuoteMeta(termStr)))
-					matches := scanTermRegex.FindAllStringSubmatchIndex(textStr, -1)
-
-					for _, match := range matches {
-						word := textStr[match[2]:match[3]] // Extract the matched word (first capturing group)
-
-						// Check if term already processed in firstOnly mode. Case-sensitive tracking based on matched word.
-						if firstOnly && processedTerms[word] {
-							continue
-						}
+			// Accept only matches with a word boundary on both sides, to prevent partial matches
+			// (e.g., "go" matching "golang"), whose term is selected by its tags.
+			// Overlapping terms (e.g., "Go" and "Go modules") are resolved to a single match among the accepted ones.
+			accept := func(match Match) bool {
+				return isTokenBoundary(textStr, match.Start) && isTokenBoundary(textStr, match.End) && tags.selects(p.termMap[match.Term])
+			}
+			var matches []Match
+			if opts.Scan || opts.Glossary {
+				matches = p.matcher.FindNonOverlapping(textStr, accept)
+			}
+			for _, match := range matches {
+				addToGlossary(p.termMap[match.Term])
+			}
 
-						fullMatchStart := segment.Start + match[0]
-						fullMatchEnd := segment.Start + match[1]
+			if opts.Scan {
+				// Scan mode: convert every dictionary term found with the prebuilt matcher
+				for _, match := range matches {
+					termData := p.termMap[match.Term]
+					word := textStr[match.Start:match.End]
 
-						// Term found in dictionary, create ruby tag, escaping HTML characters
-						safeWord := html.EscapeString(word)
-						safeYomi := html.EscapeString(termData.Yomi)
-						newText := fmt.Sprintf("<ruby>%s<rt>%s</rt></ruby>", safeWord, safeYomi)
-						patches = append(patches, Patch{Start: fullMatchStart, End: fullMatchEnd, NewText: []byte(newText)})
-						if dryRun {
-							fmt.Fprintf(os.Stderr, "GENERATING PATCH (Scan Mode): Found '%s', replace with '%s' (Offset: %d-%d)\n", word, newText, fullMatchStart, fullMatchEnd)
-						}
-						processedTerms[word] = true // Mark as processed
+					fullMatchStart := segment.Start + match.Start
+					fullMatchEnd := segment.Start + match.End
+
+					// Markup of an earlier run is never converted again, nor is the reading inside it
+					if fullMatchStart < annotatedEnd {
+						continue
 					}
+					if end := renderer.Annotated(content, fullMatchStart, fullMatchEnd, termData.Yomi); end >= 0 {
+						annotatedEnd = end
+						processedTerms[termData.Term] = true
+						annotate(termData)
+						continue
+					}
+
+					if suppress.suppressed(fullMatchStart, word, termData.Term) {
+						occurrences = append(occurrences, Occurrence{Start: fullMatchStart, End: fullMatchEnd, Term: word, Yomi: termData.Yomi, Ref: termData.Ref, Source: termData.Source, Mode: ModeScan, Skip: SkipDisabled})
+						continue
+					}
+					// In firstOnly mode, skip terms already converted. Tracking is per dictionary entry,
+					// so aliases and other cases of a term count as the same term.
+					if opts.FirstOnly && processedTerms[termData.Term] {
+						occurrences = append(occurrences, Occurrence{Start: fullMatchStart, End: fullMatchEnd, Term: word, Yomi: termData.Yomi, Ref: termData.Ref, Source: termData.Source, Mode: ModeScan, Skip: SkipFirstOnly})
+						continue
+					}
+
+					// Term found in dictionary, annotate it with its reading
+					newText := renderer.Render(word, termData.Yomi, termData.Ref, termData.Alternates())
+					patches = append(patches, Patch{Start: fullMatchStart, End: fullMatchEnd, NewText: []byte(newText)})
+					if opts.DryRun {
+						logf("GENERATING PATCH (Scan Mode): Found '%s', replace with '%s' (Offset: %d-%d)\n", word, newText, fullMatchStart, fullMatchEnd)
+					}
+					occurrences = append(occurrences, Occurrence{Start: fullMatchStart, End: fullMatchEnd, Term: word, Yomi: termData.Yomi, Ref: termData.Ref, Source: termData.Source, Mode: ModeScan})
+					processedTerms[termData.Term] = true // Mark as processed
+					annotate(termData)
 				}
 			} else {
 				// Manual mode: find "word:rubi"
-				matches := rubiRegex.FindAllStringSubmatchIndex(textStr, -1)
-				if len(matches) == 0 {
-					return ast.WalkContinue, nil
-				}
-
-				for _, match := range matches {
-					fullMatchStart := segment.Start + match[0]
-					fullMatchEnd := segment.Start + match[1]
-					wordStart := segment.Start + match[2]
-					wordEnd := segment.Start + match[3]
+				for _, marker := range p.findRubiMarkers(textStr) {
+					fullMatchStart := segment.Start + marker.Start
+					fullMatchEnd := segment.Start + marker.End
+					wordStart := segment.Start + marker.WordStart
+					wordEnd := segment.Start + marker.WordEnd
 
 					originalWordStr := string(content[wordStart:wordEnd])
+					term, found := p.index.lookup(originalWordStr)
 
-					if term, found := termMap[originalWordStr]; found {
-						// Term found in dictionary, create ruby tag, escaping HTML characters
-						safeWord := html.EscapeString(originalWordStr)
-						safeYomi := html.EscapeString(term.Yomi)
-						newText := fmt.Sprintf("<ruby>%s<rt>%s</rt></ruby>", safeWord, safeYomi)
+					if suppress.suppressed(fullMatchStart, originalWordStr, term.Term) {
+						// Leave the marker as written, e.g. to show the marker syntax itself
+						occurrences = append(occurrences, Occurrence{Start: fullMatchStart, End: fullMatchEnd, Term: originalWordStr, Mode: ModeManual, Skip: SkipDisabled})
+					} else if marker.Reading != "" {
+						// Inline reading given, use it regardless of the dictionary
+						newText := renderer.Render(originalWordStr, marker.Reading, term.Ref, nil)
+						patches = append(patches, Patch{Start: fullMatchStart, End: fullMatchEnd, NewText: []byte(newText)})
+						occurrences = append(occurrences, Occurrence{Start: fullMatchStart, End: fullMatchEnd, Term: originalWordStr, Yomi: marker.Reading, Ref: term.Ref, Source: term.Source, Mode: ModeManual})
+						if opts.DryRun {
+							logf("GENERATING PATCH (Manual Mode): Found '%s:rubi(%s)', replace with '%s' (Offset: %d-%d)\n", originalWordStr, marker.Reading, newText, fullMatchStart, fullMatchEnd)
+						}
+					} else if found {
+						// Term found in dictionary, annotate it with its reading
+						newText := renderer.Render(originalWordStr, term.Yomi, term.Ref, term.Alternates())
+						annotate(term)
 						patches = append(patches, Patch{Start: fullMatchStart, End: fullMatchEnd, NewText: []byte(newText)})
-						if dryRun {
-							fmt.Fprintf(os.Stderr, "GENERATING PATCH (Manual Mode): Found '%s:rubi', replace with '%s' (Offset: %d-%d)\n", originalWordStr, newText, fullMatchStart, fullMatchEnd)
+						occurrences = append(occurrences, Occurrence{Start: fullMatchStart, End: fullMatchEnd, Term: originalWordStr, Yomi: term.Yomi, Ref: term.Ref, Source: term.Source, Mode: ModeManual})
+						if opts.DryRun {
+							logf("GENERATING PATCH (Manual Mode): Found '%s:rubi', replace with '%s' (Offset: %d-%d)\n", originalWordStr, newText, fullMatchStart, fullMatchEnd)
 						}
 					} else {
-						// Term not found, remove ":rubi" suffix
-						patches = append(patches, Patch{Start: wordEnd, End: fullMatchEnd, NewText: []byte("")})
-						if dryRun {
-							fmt.Fprintf(os.Stderr, "WARNING (Manual Mode): Term '%s' not found in dictionary. The ':rubi' suffix would be removed (dry-run mode, no changes applied).\n", originalWordStr)
+						// Term not found, remove ":rubi" suffix (and the braces of the extended form)
+						patches = append(patches, Patch{Start: fullMatchStart, End: fullMatchEnd, NewText: []byte(originalWordStr)})
+						occurrences = append(occurrences, Occurrence{Start: fullMatchStart, End: fullMatchEnd, Term: originalWordStr, Mode: ModeManual, Skip: SkipUnknownTerm})
+						if opts.DryRun {
+							logf("WARNING (Manual Mode): Term '%s' not found in dictionary. The ':rubi' suffix would be removed (dry-run mode, no changes applied).\n", originalWordStr)
 						} else {
-							fmt.Fprintf(os.Stderr, "WARNING (Manual Mode): Term '%s' not found in dictionary. Removing ':rubi' suffix.\n", originalWordStr)
+							logf("WARNING (Manual Mode): Term '%s' not found in dictionary. Removing ':rubi' suffix.\n", originalWordStr)
 						}
 					}
 				}
@@ -171,9 +429,164 @@ func ProcessMarkdown(content []byte, dryRun bool, scan bool, firstOnly bool, ter
 		return nil, fmt.Errorf("error during AST traversal: %w", err)
 	}
 
-	if dryRun || len(patches) == 0 {
-		return content, nil
+	// Sections added at the end of the document, e.g. footnote definitions, separated by blank lines
+	var footer []string
+	if f := renderer.Finish(); f != "" {
+		footer = append(footer, f)
+	}
+	// replaceSection replaces the text at [start, end) with section unless it is already up to date
+	replaceSection := func(name string, start, end int, section string) {
+		if section != string(content[start:end]) {
+			patches = append(patches, Patch{Start: start, End: end, NewText: []byte(section)})
+			if opts.DryRun {
+				logf("GENERATING PATCH (Section): Update the '%s' section (Offset: %d-%d)\n", name, start, end)
+			}
+		}
 	}
+	if opts.Glossary {
+		section := renderGlossarySection(glossary, opts.Alternates == AlternatesGlossary)
+		if start, end, ok := findSection(comments, content, glossarySection); ok {
+			if section == "" {
+				section = glossaryMarker + "\n" // Keep the position for when terms are added again
+			}
+			replaceSection(glossarySection, start, end, section)
+		} else if start, ok := comments[glossaryMarker]; ok {
+			end := start + len(glossaryMarker)
+			if end < len(content) && content[end] == '\n' {
+				end++
+			}
+			if section != "" {
+				replaceSection(glossarySection, start, end, section)
+			}
+		} else if section != "" {
+			footer = append(footer, section)
+		}
+	}
+	if opts.Ref == RefFootnote {
+		section := renderRefsSection(annotated)
+		if start, end, ok := findSection(comments, content, refsSection); ok {
+			replaceSection(refsSection, start, end, section)
+		} else if section != "" {
+			footer = append(footer, section)
+		}
+	}
+	if len(footer) > 0 {
+		separator := "\n"
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			separator = "\n\n"
+		}
+		newText := separator + strings.Join(footer, "\n")
+		patches = append(patches, Patch{Start: len(content), End: len(content), NewText: []byte(newText)})
+		if opts.DryRun {
+			logf("GENERATING PATCH (Append): Append '%s' (Offset: %d)\n", strings.TrimSuffix(newText, "\n"), len(content))
+		}
+	}
+
+	result := &Result{Content: content, Patches: patches, Occurrences: occurrences}
+	if opts.DryRun || len(patches) == 0 {
+		return result, nil
+	}
+
+	newContent, err := ApplyPatches(content, patches)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = newContent
+	return result, nil
+}
+
+// lineColumn converts a byte offset in content to a 1-based line and column.
+// The column counts characters (runes), not bytes.
+func lineColumn(content []byte, offset int) (int, int) {
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCount(before[lineStart:]) + 1
+}
+
+// findRubiMarkers finds every "word:rubi" marker in text.
+// The marked word is the longest dictionary term that ends right before ":rubi" and
+// starts on a token boundary, so terms such as "Go言語", "Node.js" or "C++" can be marked.
+// When no dictionary term fits, the word is the last token before ":rubi" (e.g. "言語" in "Go言語:rubi").
+//
+// The extended form "{Visual Studio Code}:rubi" marks exactly the text between the braces.
+// Braces are used rather than square brackets because "[term]:rubi" at the start of a
+// line is a link reference definition in CommonMark and never reaches the walker.
+// Either form may be followed by an inline reading, as in "Kubernetes:rubi(クバネティス)".
+func (p *Processor) findRubiMarkers(text string) []rubiMarker {
+	if !strings.Contains(text, rubiSuffix) {
+		return nil
+	}
+	candidates := p.matcher.FindAll(text)
+
+	var markers []rubiMarker
+	prevEnd := 0
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], rubiSuffix)
+		if i < 0 {
+			break
+		}
+		wordEnd := offset + i
+		end := wordEnd + len(rubiSuffix)
+		reading, readingLen := parseInlineReading(text[end:])
+		end += readingLen
+		offset = end
+		if readingLen == 0 && !isTokenBoundary(text, end) {
+			continue // e.g. "Vite:rubify"
+		}
+
+		if open, ok := findBracedWord(text[prevEnd:wordEnd]); ok {
+			markers = append(markers, rubiMarker{Start: prevEnd + open, WordStart: prevEnd + open + 1, WordEnd: wordEnd - 1, End: end, Reading: reading})
+			prevEnd = end
+			continue
+		}
 
-	return ApplyPatches(content, patches)
+		start := -1
+		for _, c := range candidates {
+			if c.End == wordEnd && c.Start >= prevEnd && isTokenBoundary(text, c.Start) && (start < 0 || c.Start < start) {
+				start = c.Start
+			}
+		}
+		if start < 0 {
+			start = prevEnd + lastTokenStart(text[prevEnd:wordEnd])
+		}
+		if start == wordEnd {
+			continue // Nothing to annotate before ":rubi"
+		}
+
+		markers = append(markers, rubiMarker{Start: start, WordStart: start, WordEnd: wordEnd, End: end, Reading: reading})
+		prevEnd = end
+	}
+	return markers
+}
+
+// parseInlineReading parses an inline reading such as "(クバネティス)" at the start of s.
+// Both ASCII and full-width parentheses are accepted. It returns the reading and the
+// number of bytes consumed, or 0 if s does not start with a non-empty reading.
+func parseInlineReading(s string) (string, int) {
+	for _, paren := range [][2]string{{"(", ")"}, {"（", "）"}} {
+		if !strings.HasPrefix(s, paren[0]) {
+			continue
+		}
+		rest := s[len(paren[0]):]
+		closing := strings.Index(rest, paren[1])
+		if closing <= 0 || strings.ContainsAny(rest[:closing], "\n"+paren[0]) {
+			return "", 0
+		}
+		return rest[:closing], len(paren[0]) + closing + len(paren[1])
+	}
+	return "", 0
+}
+
+// findBracedWord reports whether s ends with a non-empty "{...}" group and
+// returns the offset of its opening brace.
+func findBracedWord(s string) (int, bool) {
+	if !strings.HasSuffix(s, "}") {
+		return 0, false
+	}
+	open := strings.LastIndex(s[:len(s)-1], "{")
+	if open < 0 || open == len(s)-2 || strings.Contains(s[open+1:len(s)-1], "}") {
+		return 0, false
+	}
+	return open, true
 }
@@ -12,10 +12,10 @@ import (
 // Helper function to create a simplified termMap for testing
 func createTestTermMap() map[string]Term {
 	return map[string]Term{
-		"Vite":   {"Vite", "ヴィート", "https://ja.vitejs.dev/"},
-		"gRPC":   {"gRPC", "ジーアールピーシー", "https://grpc.io/"},
-		"Go":     {"Go", "ゴー", ""},
-		"golang": {"golang", "ゴーラング", ""},
+		"Vite":   {Term: "Vite", Yomi: "ヴィート", Ref: "https://ja.vitejs.dev/"},
+		"gRPC":   {Term: "gRPC", Yomi: "ジーアールピーシー", Ref: "https://grpc.io/"},
+		"Go":     {Term: "Go", Yomi: "ゴー"},
+		"golang": {Term: "golang", Yomi: "ゴーラング"},
 	}
 }
 
@@ -406,3 +406,372 @@ func TestProcessMarkdown_ScanMode(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessMarkdown_ScanModeOverlappingTerms(t *testing.T) {
+	termMap := map[string]Term{
+		"Go":         {Term: "Go", Yomi: "ゴー"},
+		"Go modules": {Term: "Go modules", Yomi: "ゴーモジュールズ"},
+		"modules":    {Term: "modules", Yomi: "モジュールズ"},
+		"Angular":    {Term: "Angular", Yomi: "アンギュラー"},
+		"AngularJS":  {Term: "AngularJS", Yomi: "アンギュラージェイエス"},
+	}
+	input := "Go modules, Go and AngularJS or Angular."
+	want := "<ruby>Go modules<rt>ゴーモジュールズ</rt></ruby>, <ruby>Go<rt>ゴー</rt></ruby> and " +
+		"<ruby>AngularJS<rt>アンギュラージェイエス</rt></ruby> or <ruby>Angular<rt>アンギュラー</rt></ruby>."
+
+	// Map iteration order is random, so run several times to check the output is stable.
+	for i := 0; i < 20; i++ {
+		got, err := ProcessMarkdown([]byte(input), false, true, false, termMap)
+		if err != nil {
+			t.Fatalf("ProcessMarkdown() unexpected error: %v", err)
+		}
+		if string(got) != want {
+			t.Fatalf("ProcessMarkdown() got = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestProcessMarkdown_JapaneseBoundaries(t *testing.T) {
+	termMap := map[string]Term{
+		"Go":                 {Term: "Go", Yomi: "ゴー"},
+		"Go言語":               {Term: "Go言語", Yomi: "ゴーげんご"},
+		"ヴィート":               {Term: "ヴィート", Yomi: "ヴィート"},
+		"Node.js":            {Term: "Node.js", Yomi: "ノードジェイエス"},
+		"C++":                {Term: "C++", Yomi: "シープラスプラス"},
+		"Visual Studio Code": {Term: "Visual Studio Code", Yomi: "ビジュアルスタジオコード"},
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		scan       bool
+		wantOutput string
+	}{
+		{
+			name:       "scan - term followed by hiragana",
+			input:      "Goは速い。",
+			scan:       true,
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby>は速い。",
+		},
+		{
+			name:       "scan - term containing kanji wins over shorter term",
+			input:      "Go言語とGoogle",
+			scan:       true,
+			wantOutput: "<ruby>Go言語<rt>ゴーげんご</rt></ruby>とGoogle",
+		},
+		{
+			name:       "scan - katakana term is not matched inside a longer katakana word",
+			input:      "ヴィートは速いがヴィートルは知らない",
+			scan:       true,
+			wantOutput: "<ruby>ヴィート<rt>ヴィート</rt></ruby>は速いがヴィートルは知らない",
+		},
+		{
+			name:       "scan - terms with punctuation and spaces",
+			input:      "Node.jsとC++とVisual Studio Codeを使う",
+			scan:       true,
+			wantOutput: "<ruby>Node.js<rt>ノードジェイエス</rt></ruby>と<ruby>C++<rt>シープラスプラス</rt></ruby>と<ruby>Visual Studio Code<rt>ビジュアルスタジオコード</rt></ruby>を使う",
+		},
+		{
+			name:       "manual - marker directly after kana",
+			input:      "これはGo:rubiです",
+			wantOutput: "これは<ruby>Go<rt>ゴー</rt></ruby>です",
+		},
+		{
+			name:       "manual - term containing kanji",
+			input:      "Go言語:rubiで書く",
+			wantOutput: "<ruby>Go言語<rt>ゴーげんご</rt></ruby>で書く",
+		},
+		{
+			name:       "manual - katakana term",
+			input:      "ヴィート:rubiを使う",
+			wantOutput: "<ruby>ヴィート<rt>ヴィート</rt></ruby>を使う",
+		},
+		{
+			name:       "manual - dotted term",
+			input:      "Use Node.js:rubi today",
+			wantOutput: "Use <ruby>Node.js<rt>ノードジェイエス</rt></ruby> today",
+		},
+		{
+			name:       "manual - marker must end on a boundary",
+			input:      "Go:rubify",
+			wantOutput: "Go:rubify",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessMarkdown([]byte(tt.input), false, tt.scan, false, termMap)
+			if err != nil {
+				t.Fatalf("ProcessMarkdown() unexpected error: %v", err)
+			}
+			if string(got) != tt.wantOutput {
+				t.Errorf("ProcessMarkdown() got = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestProcessMarkdown_ManualModeBracedMarker(t *testing.T) {
+	termMap := map[string]Term{
+		"Go":                 {Term: "Go", Yomi: "ゴー"},
+		"Code":               {Term: "Code", Yomi: "コード"},
+		"Next.js":            {Term: "Next.js", Yomi: "ネクストジェイエス"},
+		"Visual Studio Code": {Term: "Visual Studio Code", Yomi: "ビジュアルスタジオコード"},
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "multi-word term",
+			input:      "I use {Visual Studio Code}:rubi daily.",
+			wantOutput: "I use <ruby>Visual Studio Code<rt>ビジュアルスタジオコード</rt></ruby> daily.",
+		},
+		{
+			name:       "braces select a shorter term",
+			input:      "Visual Studio {Code}:rubi",
+			wantOutput: "Visual Studio <ruby>Code<rt>コード</rt></ruby>",
+		},
+		{
+			name:       "punctuated term at the start of a line",
+			input:      "{Next.js}:rubiを使う",
+			wantOutput: "<ruby>Next.js<rt>ネクストジェイエス</rt></ruby>を使う",
+		},
+		{
+			name:       "unknown braced term keeps the text and drops the marker",
+			input:      "{Unknown Thing}:rubi and Go:rubi",
+			wantOutput: "Unknown Thing and <ruby>Go<rt>ゴー</rt></ruby>",
+		},
+		{
+			name:       "empty braces are not a marker",
+			input:      "{}:rubi",
+			wantOutput: "{}:rubi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldStderr := os.Stderr
+			_, w, _ := os.Pipe()
+			os.Stderr = w
+			got, err := ProcessMarkdown([]byte(tt.input), false, false, false, termMap)
+			w.Close()
+			os.Stderr = oldStderr
+
+			if err != nil {
+				t.Fatalf("ProcessMarkdown() unexpected error: %v", err)
+			}
+			if string(got) != tt.wantOutput {
+				t.Errorf("ProcessMarkdown() got = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestProcessMarkdown_ManualModeInlineReading(t *testing.T) {
+	testTermMap := createTestTermMap()
+
+	tests := []struct {
+		name       string
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "term not in dictionary",
+			input:      "Kubernetes:rubi(クバネティス) is everywhere.",
+			wantOutput: "<ruby>Kubernetes<rt>クバネティス</rt></ruby> is everywhere.",
+		},
+		{
+			name:       "inline reading overrides the dictionary",
+			input:      "Vite:rubi(ヴァイト)",
+			wantOutput: "<ruby>Vite<rt>ヴァイト</rt></ruby>",
+		},
+		{
+			name:       "full-width parentheses",
+			input:      "山田:rubi（やまだ）さん",
+			wantOutput: "<ruby>山田<rt>やまだ</rt></ruby>さん",
+		},
+		{
+			name:       "braced word with reading",
+			input:      "{Project Nova}:rubi(プロジェクトノヴァ)",
+			wantOutput: "<ruby>Project Nova<rt>プロジェクトノヴァ</rt></ruby>",
+		},
+		{
+			name:       "reading is HTML escaped",
+			input:      `Foo:rubi("ふー")`,
+			wantOutput: "<ruby>Foo<rt>&#34;ふー&#34;</rt></ruby>",
+		},
+		{
+			name:       "empty parentheses fall back to the dictionary",
+			input:      "Vite:rubi()",
+			wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby>()",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessMarkdown([]byte(tt.input), false, false, false, testTermMap)
+			if err != nil {
+				t.Fatalf("ProcessMarkdown() unexpected error: %v", err)
+			}
+			if string(got) != tt.wantOutput {
+				t.Errorf("ProcessMarkdown() got = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestProcess_ExistingRuby(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "scan - existing ruby is not nested",
+			opts:       Options{Scan: true},
+			input:      "<ruby>Vite<rt>ヴィート</rt></ruby> and Go",
+			wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby> and <ruby>Go<rt>ゴー</rt></ruby>",
+		},
+		{
+			name:       "scan - existing ruby counts as the first occurrence",
+			opts:       Options{Scan: true, FirstOnly: true},
+			input:      "<ruby>Vite<rt>ヴィート</rt></ruby> and Vite",
+			wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby> and Vite",
+		},
+		{
+			name:       "scan - stale reading is kept without refresh",
+			opts:       Options{Scan: true},
+			input:      "<ruby>Vite<rt>ヴァイト</rt></ruby>",
+			wantOutput: "<ruby>Vite<rt>ヴァイト</rt></ruby>",
+		},
+		{
+			name:       "scan - stale reading is refreshed",
+			opts:       Options{Scan: true, Refresh: true},
+			input:      "<ruby>Vite<rt>ヴァイト</rt></ruby> and <ruby>Go<rt>ゴー</rt></ruby>",
+			wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby> and <ruby>Go<rt>ゴー</rt></ruby>",
+		},
+		{
+			name:       "scan - ruby with attributes is not nested",
+			opts:       Options{Scan: true},
+			input:      `<ruby class="r">Vite<rt>ヴィート</rt></ruby> and Vite`,
+			wantOutput: `<ruby class="r">Vite<rt>ヴィート</rt></ruby> and <ruby>Vite<rt>ヴィート</rt></ruby>`,
+		},
+		{
+			name:       "scan - ruby with rb and upper case tags is not nested",
+			opts:       Options{Scan: true},
+			input:      "<ruby><rb>Vite</rb><rt>ヴィート</rt></ruby> and <RUBY>Go<RT>ゴー</RT></RUBY>",
+			wantOutput: "<ruby><rb>Vite</rb><rt>ヴィート</rt></ruby> and <RUBY>Go<RT>ゴー</RT></RUBY>",
+		},
+		{
+			name:       "scan - refresh leaves ruby not generated by rubi alone",
+			opts:       Options{Scan: true, Refresh: true},
+			input:      `<ruby class="r">Vite<rt>ヴァイト</rt></ruby>`,
+			wantOutput: `<ruby class="r">Vite<rt>ヴァイト</rt></ruby>`,
+		},
+		{
+			name:       "scan - unclosed ruby does not reach into the next paragraph",
+			opts:       Options{Scan: true},
+			input:      "<ruby>Go\n\nVite</ruby>",
+			wantOutput: "<ruby><ruby>Go<rt>ゴー</rt></ruby>\n\n<ruby>Vite<rt>ヴィート</rt></ruby></ruby>",
+		},
+		{
+			name:       "manual - refresh ignores terms missing from the dictionary",
+			opts:       Options{Refresh: true},
+			input:      "<ruby>Nuxt<rt>ナクスト</rt></ruby> and Go:rubi",
+			wantOutput: "<ruby>Nuxt<rt>ナクスト</rt></ruby> and <ruby>Go<rt>ゴー</rt></ruby>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestProcess_Idempotent(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+	input := "# Go\n\nVite and gRPC with Go.\n\n- Vite again\n"
+
+	for _, opts := range []Options{{Scan: true}, {Scan: true, FirstOnly: true}} {
+		first, err := processor.Process([]byte(input), opts)
+		if err != nil {
+			t.Fatalf("Process() unexpected error: %v", err)
+		}
+		second, err := processor.Process(first.Content, opts)
+		if err != nil {
+			t.Fatalf("Process() unexpected error: %v", err)
+		}
+		if string(second.Content) != string(first.Content) {
+			t.Errorf("Process(%+v) is not idempotent: first = %q, second = %q", opts, first.Content, second.Content)
+		}
+	}
+}
+
+func TestProcess_AliasesAndCase(t *testing.T) {
+	caseSensitive := false
+	k8s := Term{Term: "Kubernetes", Yomi: "クバネティス", Aliases: []string{"K8s"}, CaseSensitive: &caseSensitive}
+	processor := NewProcessor(map[string]Term{
+		"Kubernetes": k8s,
+		"K8s":        k8s,
+		"Go":         {Term: "Go", Yomi: "ゴー"},
+	})
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "scan - aliases and other cases keep their spelling",
+			opts:       Options{Scan: true},
+			input:      "Kubernetes, kubernetes, K8S and k8s",
+			wantOutput: "<ruby>Kubernetes<rt>クバネティス</rt></ruby>, <ruby>kubernetes<rt>クバネティス</rt></ruby>, <ruby>K8S<rt>クバネティス</rt></ruby> and <ruby>k8s<rt>クバネティス</rt></ruby>",
+		},
+		{
+			name:       "scan - case-sensitive terms still need the exact case",
+			opts:       Options{Scan: true},
+			input:      "Go and go",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby> and go",
+		},
+		{
+			name:       "scan - first-only counts aliases as the same term",
+			opts:       Options{Scan: true, FirstOnly: true},
+			input:      "K8s and Kubernetes",
+			wantOutput: "<ruby>K8s<rt>クバネティス</rt></ruby> and Kubernetes",
+		},
+		{
+			name:       "manual - alias and other case",
+			opts:       Options{},
+			input:      "k8s:rubi and KUBERNETES:rubi",
+			wantOutput: "<ruby>k8s<rt>クバネティス</rt></ruby> and <ruby>KUBERNETES<rt>クバネティス</rt></ruby>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+		})
+	}
+}
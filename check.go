@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// handleCheckCommand runs ProcessMarkdown over the inputs without writing anything.
+// It prints a file:line:column diagnostic for every term that would be converted
+// and every ":rubi" marker whose term is missing from the dictionary (and, with
+// --refresh, every ruby reading that is out of date), and fails
+// if there was any, so CI can reject documents that are not up to date.
+func handleCheckCommand(cfg *Config) error {
+	if len(cfg.Inputs) == 0 && stdinIsPipe() {
+		cfg.Inputs = []string{stdinPath}
+	}
+	if len(cfg.Inputs) == 0 {
+		checkFlagSet.Usage()
+		return fmt.Errorf("an input file is required for check")
+	}
+	if cfg.FirstOnly && !cfg.Scan {
+		return fmt.Errorf("the --first-only flag is only valid in -s (scan) mode")
+	}
+	if (len(cfg.OnlyTags) > 0 || len(cfg.ExcludeTags) > 0) && !cfg.Scan {
+		return fmt.Errorf("the --only-tags and --exclude-tags flags are only valid in -s (scan) mode")
+	}
+	if _, err := newRenderer(cfg.Renderer, cfg.Ref, cfg.Alternates); err != nil {
+		return err
+	}
+	if _, err := newNodeFilter(cfg.ExcludeNodes); err != nil {
+		return err
+	}
+	if err := validateFirstOnlyScope(cfg); err != nil {
+		return err
+	}
+
+	termMap, err := LoadDictionaries(cfg.DictPaths)
+	if err != nil {
+		return err
+	}
+	if err := validateTags(termMap, cfg.OnlyTags, cfg.ExcludeTags); err != nil {
+		return err
+	}
+	processor := NewProcessor(termMap)
+
+	files, err := resolveInputs(cfg.Inputs, cfg.Include, cfg.Exclude)
+	if err != nil {
+		return err
+	}
+
+	diagnostics := make([][]string, len(files))
+	errs := make([]error, len(files))
+	runParallel(len(files), cfg.Jobs, func(i int) {
+		diagnostics[i], errs[i] = checkFile(processor, cfg, files[i])
+	})
+
+	problems, badFiles := 0, 0
+	for i := range files {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", errs[i])
+			badFiles++
+			continue
+		}
+		for _, d := range diagnostics[i] {
+			fmt.Println(d)
+		}
+		if len(diagnostics[i]) > 0 {
+			problems += len(diagnostics[i])
+			badFiles++
+		}
+	}
+
+	if badFiles > 0 {
+		return fmt.Errorf("check failed: %d problem(s) in %d of %d file(s)", problems, badFiles, len(files))
+	}
+	fmt.Printf("%d file(s) checked, no problems found.\n", len(files))
+	return nil
+}
+
+// checkFile processes a single file and returns its diagnostics.
+func checkFile(processor *Processor, cfg *Config, path string) ([]string, error) {
+	content, err := readInput(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := processor.Process(content, Options{
+		Scan:           cfg.Scan,
+		FirstOnly:      cfg.FirstOnly,
+		FirstOnlyScope: cfg.FirstOnlyScope,
+		Quiet:          true,
+		Refresh:        cfg.Refresh,
+		Renderer:       cfg.Renderer,
+		Ref:            cfg.Ref,
+		Alternates:     cfg.Alternates,
+		Glossary:       cfg.Glossary,
+		ExcludeNodes:   cfg.ExcludeNodes,
+		OnlyTags:       cfg.OnlyTags,
+		ExcludeTags:    cfg.ExcludeTags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to process markdown in '%s': %w", path, err)
+	}
+
+	name := displayName(path)
+	var diagnostics []string
+	for _, o := range result.Occurrences {
+		line, column := lineColumn(content, o.Start)
+		switch {
+		case o.Skip == "" && o.Mode == ModeRefresh:
+			diagnostics = append(diagnostics, fmt.Sprintf("%s:%d:%d: reading of '%s' is out of date (dictionary: '%s')", name, line, column, o.Term, o.Yomi))
+		case o.Skip == "":
+			diagnostics = append(diagnostics, fmt.Sprintf("%s:%d:%d: '%s' would be converted (%s mode)", name, line, column, o.Term, o.Mode))
+		case o.Skip == SkipUnknownTerm:
+			diagnostics = append(diagnostics, fmt.Sprintf("%s:%d:%d: unknown term '%s' is not in the dictionary", name, line, column, o.Term))
+		}
+	}
+
+	// Changes that are not tied to a term still mean the file is not up to date
+	if len(diagnostics) == 0 && !bytes.Equal(content, result.Content) {
+		diagnostics = append(diagnostics, fmt.Sprintf("%s: would be changed", name))
+	}
+	return diagnostics, nil
+}
+
+// displayName returns the name used for path in messages.
+func displayName(path string) string {
+	if path == stdinPath {
+		return "<stdin>"
+	}
+	return path
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// --- Test handleCheckCommand ---
+
+func TestHandleCheckCommand(t *testing.T) {
+	dir := t.TempDir()
+	dictFile := filepath.Join(dir, "dict.yaml")
+	writeTestFile(t, dictFile, testDictYAML)
+
+	clean := filepath.Join(dir, "clean.md")
+	writeTestFile(t, clean, "# Title\n\nNothing to convert.\n")
+	unknown := filepath.Join(dir, "unknown.md")
+	writeTestFile(t, unknown, "# Title\n\nUse Nuxt:rubi here.\n")
+	pending := filepath.Join(dir, "pending.md")
+	writeTestFile(t, pending, "Intro\n\nこれはVite です。\n")
+	stale := filepath.Join(dir, "stale.md")
+	writeTestFile(t, stale, "<ruby>Vite<rt>ヴァイト</rt></ruby>\n")
+	aozora := filepath.Join(dir, "aozora.md")
+	writeTestFile(t, aozora, "｜Vite《ヴィート》 is fast.\n")
+
+	tests := []struct {
+		name        string
+		cfg         *Config
+		wantOutput  []string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:       "clean file passes",
+			cfg:        &Config{DictPaths: []string{dictFile}, Jobs: 1, Inputs: []string{clean}},
+			wantOutput: []string{"1 file(s) checked, no problems found."},
+		},
+		{
+			name:        "unknown marker fails with position",
+			cfg:         &Config{DictPaths: []string{dictFile}, Jobs: 1, Inputs: []string{unknown}},
+			wantOutput:  []string{unknown + ":3:5: unknown term 'Nuxt' is not in the dictionary"},
+			wantErr:     true,
+			errContains: "check failed: 1 problem(s) in 1 of 1 file(s)",
+		},
+		{
+			name:        "scan mode reports terms that would be converted",
+			cfg:         &Config{DictPaths: []string{dictFile}, Scan: true, Jobs: 2, Inputs: []string{clean, pending}},
+			wantOutput:  []string{pending + ":3:4: 'Vite' would be converted (scan mode)"},
+			wantErr:     true,
+			errContains: "check failed: 1 problem(s) in 1 of 2 file(s)",
+		},
+		{
+			name:       "manual mode ignores unmarked terms",
+			cfg:        &Config{DictPaths: []string{dictFile}, Jobs: 1, Inputs: []string{pending}},
+			wantOutput: []string{"1 file(s) checked, no problems found."},
+		},
+		{
+			name:       "existing ruby is not reported again",
+			cfg:        &Config{DictPaths: []string{dictFile}, Scan: true, Jobs: 1, Inputs: []string{stale}},
+			wantOutput: []string{"1 file(s) checked, no problems found."},
+		},
+		{
+			name:        "refresh reports stale readings",
+			cfg:         &Config{DictPaths: []string{dictFile}, Scan: true, Refresh: true, Jobs: 1, Inputs: []string{stale}},
+			wantOutput:  []string{stale + ":1:1: reading of 'Vite' is out of date (dictionary: 'ヴィート')"},
+			wantErr:     true,
+			errContains: "check failed: 1 problem(s) in 1 of 1 file(s)",
+		},
+		{
+			name:       "documents are checked with their renderer",
+			cfg:        &Config{DictPaths: []string{dictFile}, Scan: true, Renderer: "aozora", Jobs: 1, Inputs: []string{aozora}},
+			wantOutput: []string{"1 file(s) checked, no problems found."},
+		},
+		{
+			name:        "a missing glossary is reported",
+			cfg:         &Config{DictPaths: []string{dictFile}, Scan: true, Renderer: "aozora", Glossary: true, Jobs: 1, Inputs: []string{aozora}},
+			wantOutput:  []string{aozora + ": would be changed"},
+			wantErr:     true,
+			errContains: "check failed: 1 problem(s) in 1 of 1 file(s)",
+		},
+		{
+			name:        "unknown renderer",
+			cfg:         &Config{DictPaths: []string{dictFile}, Renderer: "latex", Jobs: 1, Inputs: []string{aozora}},
+			wantErr:     true,
+			errContains: "unknown renderer 'latex'",
+		},
+		{
+			name:        "first-only requires scan mode",
+			cfg:         &Config{DictPaths: []string{dictFile}, FirstOnly: true, Jobs: 1, Inputs: []string{pending}},
+			wantErr:     true,
+			errContains: "only valid in -s (scan) mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			got := captureStdout(t, func() { err = handleCheckCommand(tt.cfg) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handleCheckCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("handleCheckCommand() error message = %q, want error message containing %q", err.Error(), tt.errContains)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(got, want) {
+					t.Errorf("handleCheckCommand() stdout = %q, want output containing %q", got, want)
+				}
+			}
+		})
+	}
+
+	// Checking never modifies files
+	if content, _ := os.ReadFile(pending); string(content) != "Intro\n\nこれはVite です。\n" {
+		t.Errorf("pending.md content = %q, want it unchanged", content)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfigName is the name of the project configuration file. Like .editorconfig,
+// it is looked up from the directory of the first input up to the root.
+const projectConfigName = ".rubi.yaml"
+
+// Modes accepted by the "mode" setting of the project configuration.
+const (
+	configModeManual = "manual"
+	configModeScan   = "scan"
+)
+
+// projectConfig is the content of a project configuration file. Settings that are
+// not given keep the flag defaults, and flags given on the command line take precedence.
+type projectConfig struct {
+	Dictionary     stringList `yaml:"dictionary"` // One path or a list, relative to the configuration file
+	Mode           string     `yaml:"mode"`       // manual or scan
+	FirstOnly      *bool      `yaml:"firstOnly"`
+	FirstOnlyScope string     `yaml:"firstOnlyScope"`
+	Renderer       string     `yaml:"renderer"`
+	Alternates     string     `yaml:"alternates"`
+	Include        []string   `yaml:"include"` // Glob patterns relative to the configuration file
+	Exclude        []string   `yaml:"exclude"` // Glob patterns relative to the configuration file
+	ExcludeNodes   []string   `yaml:"excludeNodes"`
+	OnlyTags       []string   `yaml:"onlyTags"`
+	ExcludeTags    []string   `yaml:"excludeTags"`
+}
+
+// findProjectConfig returns the path of the project configuration file that applies
+// to the given inputs, or "" if there is none.
+func findProjectConfig(inputs []string) (string, error) {
+	start := "."
+	if len(inputs) > 0 && inputs[0] != stdinPath {
+		start = inputs[0]
+		for strings.ContainsAny(start, "*?[") { // Start from the directory part of a glob pattern
+			start = filepath.Dir(start)
+		}
+		if info, err := os.Stat(start); err != nil || !info.IsDir() {
+			start = filepath.Dir(start)
+		}
+	}
+
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", fmt.Errorf("failed to find %s: %w", projectConfigName, err)
+	}
+	for {
+		path := filepath.Join(dir, projectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// loadProjectConfig reads and parses the project configuration file at path.
+// Unknown settings are reported so that typos do not go unnoticed.
+func loadProjectConfig(path string) (*projectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var pc projectConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&pc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file '%s': %w", path, err)
+	}
+	switch pc.Mode {
+	case "", configModeManual, configModeScan:
+	default:
+		return nil, fmt.Errorf("invalid mode '%s' in '%s' (expected manual or scan)", pc.Mode, path)
+	}
+	return &pc, nil
+}
+
+// applyProjectConfig merges the project configuration into cfg. The file is
+// cfg.ConfigPath if given, otherwise the one found by findProjectConfig.
+// Only settings that have a flag in flags are applied, and flags set on the
+// command line win over the file.
+func applyProjectConfig(cfg *Config, flags *flag.FlagSet) error {
+	path := cfg.ConfigPath
+	if path == "" {
+		var err error
+		if path, err = findProjectConfig(cfg.Inputs); err != nil || path == "" {
+			return err
+		}
+	}
+	pc, err := loadProjectConfig(path)
+	if err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	applies := func(name string) bool {
+		return flags.Lookup(name) != nil && !set[name]
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("failed to resolve config file '%s': %w", path, err)
+	}
+	if applies("d") {
+		// Without a "dictionary" setting, the dictionary next to the configuration file is used
+		cfg.DictPaths = nil
+		for _, dictionary := range pc.Dictionary.valuesOr(defaultDictPath) {
+			cfg.DictPaths = append(cfg.DictPaths, resolveConfigPath(dir, dictionary))
+		}
+	}
+	if cfg.Check { // Validating the dictionary only needs its path
+		return nil
+	}
+	if pc.Mode != "" && applies("s") {
+		cfg.Scan = pc.Mode == configModeScan
+	}
+	// The first-only settings only apply where they are valid, so that "-s=false" still works
+	if pc.FirstOnly != nil && applies("first-only") && cfg.Scan {
+		cfg.FirstOnly = *pc.FirstOnly
+	}
+	if pc.FirstOnlyScope != "" && applies("first-only-scope") && cfg.FirstOnly {
+		cfg.FirstOnlyScope = pc.FirstOnlyScope
+	}
+	// Like the first-only settings, the tag filters only apply in scan mode
+	if pc.OnlyTags != nil && applies("only-tags") && cfg.Scan {
+		cfg.OnlyTags = pc.OnlyTags
+	}
+	if pc.ExcludeTags != nil && applies("exclude-tags") && cfg.Scan {
+		cfg.ExcludeTags = pc.ExcludeTags
+	}
+	if pc.Renderer != "" && applies("renderer") {
+		cfg.Renderer = pc.Renderer
+	}
+	if pc.Alternates != "" && applies("alternates") {
+		cfg.Alternates = pc.Alternates
+	}
+	if pc.ExcludeNodes != nil && applies("exclude-nodes") {
+		cfg.ExcludeNodes = pc.ExcludeNodes
+	}
+	for _, pattern := range pc.Include {
+		cfg.Include = append(cfg.Include, resolveConfigPath(dir, pattern))
+	}
+	for _, pattern := range pc.Exclude {
+		cfg.Exclude = append(cfg.Exclude, resolveConfigPath(dir, pattern))
+	}
+	return nil
+}
+
+// stringList is a list of strings that can be given as a repeatable flag, or in
+// YAML as either a single string or a sequence of strings.
+type stringList []string
+
+// newStringList defines a repeatable flag on flags that collects every value given.
+func newStringList(flags *flag.FlagSet, name, usage string) *stringList {
+	l := new(stringList)
+	flags.Var(l, name, usage)
+	return l
+}
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = stringList{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// valuesOr returns the values in l, or defaults if there are none.
+func (l stringList) valuesOr(defaults ...string) []string {
+	if len(l) == 0 {
+		return defaults
+	}
+	return l
+}
+
+// resolveConfigPath returns p, written in a configuration file in dir, as an absolute path.
+func resolveConfigPath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(dir, filepath.FromSlash(p))
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "project/docs/guide/a.md", "other/b.md")
+	configFile := filepath.Join(dir, "project", projectConfigName)
+	writeTestFile(t, configFile, "mode: scan\n")
+
+	tests := []struct {
+		name   string
+		inputs []string
+		want   string
+	}{
+		{name: "file in a subdirectory", inputs: []string{filepath.Join(dir, "project/docs/guide/a.md")}, want: configFile},
+		{name: "directory", inputs: []string{filepath.Join(dir, "project/docs")}, want: configFile},
+		{name: "glob pattern", inputs: []string{filepath.Join(dir, "project/docs/**/*.md")}, want: configFile},
+		{name: "directory of the config file", inputs: []string{filepath.Join(dir, "project")}, want: configFile},
+		{name: "first input decides", inputs: []string{filepath.Join(dir, "other/b.md"), filepath.Join(dir, "project/docs")}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findProjectConfig(tt.inputs)
+			if err != nil {
+				t.Fatalf("findProjectConfig() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("findProjectConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		want        *projectConfig
+		errContains string
+	}{
+		{
+			name:    "all settings",
+			content: "dictionary: dicts/tech.yaml\nmode: scan\nfirstOnly: true\nfirstOnlyScope: section\nrenderer: aozora\ninclude: [\"docs/**/*.md\"]\nexclude: [\"docs/drafts/**\"]\nexcludeNodes: [heading, link]\n",
+			want: &projectConfig{
+				Dictionary:     stringList{"dicts/tech.yaml"},
+				Mode:           configModeScan,
+				FirstOnly:      func() *bool { b := true; return &b }(),
+				FirstOnlyScope: "section",
+				Renderer:       "aozora",
+				Include:        []string{"docs/**/*.md"},
+				Exclude:        []string{"docs/drafts/**"},
+				ExcludeNodes:   []string{"heading", "link"},
+			},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    &projectConfig{},
+		},
+		{
+			name:        "unknown setting",
+			content:     "modes: scan\n",
+			errContains: "field modes not found",
+		},
+		{
+			name:        "invalid mode",
+			content:     "mode: auto\n",
+			errContains: "invalid mode 'auto'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), projectConfigName)
+			writeTestFile(t, path, tt.content)
+
+			got, err := loadProjectConfig(path)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("loadProjectConfig() error = %v, want error containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadProjectConfig() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadProjectConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "docs/a.md")
+	writeTestFile(t, filepath.Join(dir, projectConfigName), "dictionary: dicts/tech.yaml\nmode: scan\nfirstOnly: true\nfirstOnlyScope: section\nrenderer: aozora\ninclude: [\"docs/**\"]\nexclude: [\"docs/drafts/**\"]\nexcludeNodes: [heading]\n")
+	input := filepath.Join(dir, "docs", "a.md")
+
+	tests := []struct {
+		name string
+		args []string
+		want Config
+	}{
+		{
+			name: "settings from the file",
+			args: []string{input},
+			want: Config{
+				DictPaths:      []string{filepath.Join(dir, "dicts", "tech.yaml")},
+				Scan:           true,
+				FirstOnly:      true,
+				FirstOnlyScope: "section",
+				Renderer:       "aozora",
+				ExcludeNodes:   []string{"heading"},
+				Include:        []string{filepath.Join(dir, "docs", "**")},
+				Exclude:        []string{filepath.Join(dir, "docs", "drafts", "**")},
+			},
+		},
+		{
+			name: "flags win over the file",
+			args: []string{"-d", "my.yaml", "-renderer", "html", "-exclude-nodes", "", "-first-only-scope", "page", input},
+			want: Config{
+				DictPaths:      []string{"my.yaml"},
+				Scan:           true,
+				FirstOnly:      true,
+				FirstOnlyScope: "page",
+				Renderer:       "html",
+				Include:        []string{filepath.Join(dir, "docs", "**")},
+				Exclude:        []string{filepath.Join(dir, "docs", "drafts", "**")},
+			},
+		},
+		{
+			name: "manual mode from the command line ignores first-only",
+			args: []string{"-s=false", input},
+			want: Config{
+				DictPaths:      []string{filepath.Join(dir, "dicts", "tech.yaml")},
+				FirstOnlyScope: scopePage,
+				Renderer:       "aozora",
+				ExcludeNodes:   []string{"heading"},
+				Include:        []string{filepath.Join(dir, "docs", "**")},
+				Exclude:        []string{filepath.Join(dir, "docs", "drafts", "**")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			dictPaths := newStringList(flags, "d", "")
+			scan := flags.Bool("s", false, "")
+			firstOnly := flags.Bool("first-only", false, "")
+			firstOnlyScope := flags.String("first-only-scope", scopePage, "")
+			renderer := flags.String("renderer", defaultRenderer, "")
+			excludeNodes := flags.String("exclude-nodes", "", "")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+
+			cfg := &Config{
+				DictPaths:      dictPaths.valuesOr(defaultDictPath),
+				Scan:           *scan,
+				FirstOnly:      *firstOnly,
+				FirstOnlyScope: *firstOnlyScope,
+				Renderer:       *renderer,
+				ExcludeNodes:   parseList(*excludeNodes),
+				Inputs:         flags.Args(),
+			}
+			if err := applyProjectConfig(cfg, flags); err != nil {
+				t.Fatalf("applyProjectConfig() unexpected error: %v", err)
+			}
+			tt.want.Inputs = []string{input}
+			if !reflect.DeepEqual(*cfg, tt.want) {
+				t.Errorf("applyProjectConfig() = %+v, want %+v", *cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleMainCommand_IncludeExclude(t *testing.T) {
+	dir := t.TempDir()
+	dictFile := filepath.Join(dir, "dict.yaml")
+	writeTestFile(t, dictFile, testDictYAML)
+	writeTestFiles(t, dir, "docs/a.md", "docs/drafts/b.md", "docs/c.md")
+
+	cfg := &Config{
+		DictPaths: []string{dictFile},
+		Write:     true,
+		Scan:      true,
+		Jobs:      1,
+		Inputs:    []string{filepath.Join(dir, "docs", "a.md"), filepath.Join(dir, "docs", "drafts", "b.md"), filepath.Join(dir, "docs", "c.md")},
+		Include:   []string{filepath.Join(dir, "docs", "**", "*.md")},
+		Exclude:   []string{filepath.Join(dir, "docs", "drafts", "**"), filepath.Join(dir, "docs", "c.md")},
+	}
+	captureStdout(t, func() {
+		if err := handleMainCommand(cfg); err != nil {
+			t.Fatalf("handleMainCommand() unexpected error: %v", err)
+		}
+	})
+
+	// Files named on the command line, e.g. expanded by the shell, are filtered too
+	for name, want := range map[string]string{
+		"docs/a.md":        "<ruby>Vite<rt>ヴィート</rt></ruby>\n",
+		"docs/drafts/b.md": "Vite\n",
+		"docs/c.md":        "Vite\n",
+	} {
+		content, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if string(content) != want {
+			t.Errorf("%s content = %q, want %q", name, content, want)
+		}
+	}
+
+	cfg.Inputs = []string{filepath.Join(dir, "docs", "drafts", "b.md")}
+	if err := handleMainCommand(cfg); err == nil || !strings.Contains(err.Error(), "is excluded by the include and exclude settings") {
+		t.Errorf("handleMainCommand() error = %v, want an error for an excluded file", err)
+	}
+}
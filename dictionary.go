@@ -2,7 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,36 +16,280 @@ type Term struct {
 	Term string `yaml:"term"`
 	Yomi string `yaml:"yomi"`
 	Ref  string `yaml:"ref,omitempty"`
+	// Priority decides which term wins when matches overlap in scan mode.
+	// Higher values win; terms with equal priority use leftmost-longest.
+	Priority int `yaml:"priority,omitempty"`
+	// Aliases are other spellings of the term, such as "K8s" for "Kubernetes".
+	// They are annotated with the same reading, keeping the spelling used in the document.
+	Aliases []string `yaml:"aliases,omitempty"`
+	// CaseSensitive controls whether the term and its aliases only match with the same
+	// case of ASCII letters. It defaults to true.
+	CaseSensitive *bool `yaml:"case_sensitive,omitempty"`
+	// Tags are categories such as "frontend", "infra" or "acronym", used to choose
+	// the terms converted in scan mode with --only-tags and --exclude-tags.
+	Tags []string `yaml:"tags,omitempty"`
+	// Readings lists the readings of a term whose reading is disputed, with exactly one
+	// marked preferred. Yomi is set to the preferred reading when the dictionary is loaded.
+	Readings []Reading `yaml:"readings,omitempty"`
+	// Source is the dictionary file the term was loaded from.
+	Source string `yaml:"-"`
+}
+
+// IsCaseSensitive reports whether the term only matches with the same case of ASCII letters.
+func (t Term) IsCaseSensitive() bool {
+	return t.CaseSensitive == nil || *t.CaseSensitive
+}
+
+// Spellings returns the term followed by its aliases.
+func (t Term) Spellings() []string {
+	return append([]string{t.Term}, t.Aliases...)
 }
 
 // Dictionary represents the structure of the dictionary file.
+// The terms are kept as YAML nodes so that errors can point at their line.
 type Dictionary struct {
-	Terms []Term `yaml:"terms"`
+	Terms []yaml.Node `yaml:"terms"`
+}
+
+// defaultDictPath is the dictionary used when none is given.
+const defaultDictPath = "dict.yaml"
+
+// dictionaryExtensions lists the file extensions loaded from a dictionary directory.
+var dictionaryExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+}
+
+// location is a line in a dictionary file, used in error messages.
+type location struct {
+	Path string
+	Line int
 }
 
-// LoadDictionary loads and parses the dictionary file from the given path.
-// It returns a map for efficient lookups.
+func (l location) String() string {
+	return fmt.Sprintf("%s:%d", l.Path, l.Line)
+}
+
+// LoadDictionary loads and parses the dictionary from the given path, which is
+// either a dictionary file or a directory whose YAML files (searched recursively)
+// together form one dictionary, as in dictionary/a.yaml, dictionary/go.yaml.
+// It returns a map for efficient lookups, keyed by every spelling of every term
+// (the term itself and its aliases), each mapping to the whole entry.
+// Duplicates within the dictionary are an error, reported with their file and line.
 func LoadDictionary(path string) (map[string]Term, error) {
-	data, err := os.ReadFile(path)
+	files, err := dictionaryFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	termMap := make(map[string]Term)
+	locations := make(map[string]location) // Where each spelling is defined
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dictionary file: %w", err)
+		}
+
+		var dict Dictionary
+		if err := yaml.Unmarshal(data, &dict); err != nil {
+			return nil, fmt.Errorf("failed to parse dictionary yaml '%s': %w", file, err)
+		}
+
+		for _, node := range dict.Terms {
+			var term Term
+			if err := node.Decode(&term); err != nil {
+				return nil, fmt.Errorf("failed to parse dictionary yaml '%s': %w", file, err)
+			}
+			at := location{Path: file, Line: node.Line}
+			if err := resolveReadings(&term); err != nil {
+				return nil, fmt.Errorf("invalid entry found: %w (%s)", err, at)
+			}
+			if term.Term == "" || term.Yomi == "" {
+				return nil, fmt.Errorf("invalid entry found: term and yomi are required (%s)", at)
+			}
+			term.Source = file
+			for _, spelling := range term.Spellings() {
+				if spelling == "" {
+					return nil, fmt.Errorf("invalid entry found: empty alias for term %s (%s)", term.Term, at)
+				}
+				if previous, exists := locations[spelling]; exists {
+					return nil, fmt.Errorf("duplicate term found: %s (%s and %s)", spelling, previous, at)
+				}
+				termMap[spelling] = term
+				locations[spelling] = at
+			}
+		}
+	}
+
+	// A case-insensitive spelling must not collide with any other spelling when case is ignored
+	folded := make(map[string]string) // Folded spelling -> spelling, for case-insensitive terms
+	for _, spelling := range sortedKeys(termMap) {
+		key := foldASCII(spelling)
+		if other, exists := folded[key]; exists && (!termMap[spelling].IsCaseSensitive() || !termMap[other].IsCaseSensitive()) {
+			return nil, fmt.Errorf("duplicate term found: %s (conflicts with %s when case is ignored, %s and %s)", spelling, other, locations[other], locations[spelling])
+		}
+		folded[key] = spelling
+	}
+
+	return termMap, nil
+}
+
+// dictionaryFiles returns the dictionary files at path: path itself if it is a file,
+// or every YAML file below it in lexical order if it is a directory.
+// Hidden directories such as .git are skipped.
+func dictionaryFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read dictionary file: %w", err)
 	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
 
-	var dict Dictionary
-	if err := yaml.Unmarshal(data, &dict); err != nil {
-		return nil, fmt.Errorf("failed to parse dictionary yaml: %w", err)
+	var files []string
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if dictionaryExtensions[strings.ToLower(filepath.Ext(file))] {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dictionary directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no dictionary files found in '%s'", path)
 	}
+	return files, nil
+}
+
+// dictionaryOverride records a spelling from one dictionary that replaced an entry of an earlier one.
+type dictionaryOverride struct {
+	Spelling string
+	Previous Term
+}
+
+// dictionaryLayer describes one of the dictionaries given to LoadDictionaries.
+type dictionaryLayer struct {
+	Path      string
+	Files     map[string]bool // Dictionary files of the layer, more than one for a directory
+	Overrides []dictionaryOverride
+}
+
+// LoadDictionaries loads the dictionaries (files or directories) at paths and layers them in order.
+// Each one is loaded with LoadDictionary, so duplicates within a dictionary are still
+// an error, but a term in a later dictionary overrides the same term in earlier ones.
+func LoadDictionaries(paths []string) (map[string]Term, error) {
+	termMap, _, err := loadDictionaryLayers(paths)
+	return termMap, err
+}
 
-	termMap := make(map[string]Term, len(dict.Terms))
-	for _, term := range dict.Terms {
-		if term.Term == "" || term.Yomi == "" {
-			return nil, fmt.Errorf("invalid entry found: term and yomi are required")
+// loadDictionaryLayers is LoadDictionaries that also describes every layer.
+func loadDictionaryLayers(paths []string) (map[string]Term, []dictionaryLayer, error) {
+	termMap := make(map[string]Term)
+	layers := make([]dictionaryLayer, len(paths))
+	for i, path := range paths {
+		dict, err := LoadDictionary(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		files := make(map[string]bool)
+		names, err := dictionaryFiles(path)
+		if err != nil {
+			return nil, nil, err
 		}
-		if _, exists := termMap[term.Term]; exists {
-			return nil, fmt.Errorf("duplicate term found: %s", term.Term)
+		for _, name := range names {
+			files[name] = true
 		}
-		termMap[term.Term] = term
+		layers[i] = dictionaryLayer{Path: path, Files: files, Overrides: mergeDictionary(termMap, dict)}
 	}
+	return termMap, layers, nil
+}
 
-	return termMap, nil
+// mergeDictionary adds the terms of layer to termMap, replacing the earlier entries they override.
+// An earlier entry is overridden as a whole, aliases included, by the same term or by a spelling
+// that collides with its term, taking case-insensitive terms into account, so that a term never
+// has two readings. A spelling that collides only with an alias replaces just that alias.
+func mergeDictionary(termMap, layer map[string]Term) []dictionaryOverride {
+	var overrides []dictionaryOverride
+	folded := make(map[string]bool) // Whether a folded spelling of layer belongs to a case-insensitive term
+	for spelling, term := range layer {
+		key := foldASCII(spelling)
+		folded[key] = folded[key] || !term.IsCaseSensitive()
+	}
+
+	overridden := make(map[string]bool) // Earlier entries to drop, by entryKey
+	replaced := make(map[string]bool)   // Earlier aliases to drop
+	for spelling, previous := range termMap {
+		_, sameTerm := layer[previous.Term]
+		_, sameSpelling := layer[spelling]
+		caseInsensitive, exists := folded[foldASCII(spelling)]
+		collides := sameSpelling || exists && (caseInsensitive || !previous.IsCaseSensitive())
+		switch {
+		case sameTerm || collides && spelling == previous.Term:
+			overridden[entryKey(previous)] = true
+		case collides:
+			replaced[spelling] = true
+		}
+	}
+	for _, spelling := range sortedKeys(termMap) {
+		if previous := termMap[spelling]; overridden[entryKey(previous)] || replaced[spelling] {
+			delete(termMap, spelling)
+			overrides = append(overrides, dictionaryOverride{Spelling: spelling, Previous: previous})
+		}
+	}
+
+	for spelling, term := range layer {
+		termMap[spelling] = term
+	}
+	return overrides
+}
+
+// entryKey identifies the dictionary entry a term was loaded from, shared by all of its spellings.
+func entryKey(term Term) string {
+	return term.Source + "\x00" + term.Term
+}
+
+// sortedKeys returns the keys of termMap in sorted order.
+func sortedKeys(termMap map[string]Term) []string {
+	keys := make([]string, 0, len(termMap))
+	for key := range termMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// termIndex looks up the dictionary entry for a word as written in a document.
+type termIndex struct {
+	termMap map[string]Term
+	folded  map[string]Term // Case-insensitive spellings by foldASCII
+}
+
+// newTermIndex builds a termIndex for the given term dictionary.
+func newTermIndex(termMap map[string]Term) *termIndex {
+	idx := &termIndex{termMap: termMap, folded: make(map[string]Term)}
+	for spelling, term := range termMap {
+		if !term.IsCaseSensitive() {
+			idx.folded[foldASCII(spelling)] = term
+		}
+	}
+	return idx
+}
+
+// lookup returns the entry for word, ignoring the case of terms that are not case sensitive.
+func (idx *termIndex) lookup(word string) (Term, bool) {
+	if term, found := idx.termMap[word]; found {
+		return term, true
+	}
+	term, found := idx.folded[foldASCII(word)]
+	return term, found
 }
@@ -2,11 +2,16 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 )
 
+// testDictYAML is a dictionary with only the term Vite, for tests that need any dictionary file.
+const testDictYAML = "terms:\n  - term: Vite\n    yomi: ヴィート\n"
+
 // Helper function to create a temporary dictionary file
 func createTempDictFile(t *testing.T, content string) string {
 	tmpfile, err := os.CreateTemp("", "test_dict_*.yaml")
@@ -40,8 +45,8 @@ terms:
     yomi: "ジーアールピーシー"
 `,
 			wantTermMap: map[string]Term{
-				"Vite": {"Vite", "ヴィート", "https://ja.vitejs.dev/"},
-				"gRPC": {"gRPC", "ジーアールピーシー", ""}, // Ref is omitempty, so it's not set
+				"Vite": {Term: "Vite", Yomi: "ヴィート", Ref: "https://ja.vitejs.dev/"},
+				"gRPC": {Term: "gRPC", Yomi: "ジーアールピーシー"}, // Ref is omitempty, so it's not set
 			},
 			wantErr: false,
 		},
@@ -91,6 +96,60 @@ terms:
 			wantErr:     true,
 			errContains: "duplicate term found: Vite",
 		},
+		{
+			name: "aliases map to the whole entry",
+			yamlContent: `
+terms:
+  - term: "Kubernetes"
+    yomi: "クバネティス"
+    aliases: ["K8s"]
+`,
+			wantTermMap: map[string]Term{
+				"Kubernetes": {Term: "Kubernetes", Yomi: "クバネティス", Aliases: []string{"K8s"}},
+				"K8s":        {Term: "Kubernetes", Yomi: "クバネティス", Aliases: []string{"K8s"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "alias duplicates a term",
+			yamlContent: `
+terms:
+  - term: "Angular"
+    yomi: "アンギュラー"
+  - term: "AngularJS"
+    yomi: "アンギュラージェイエス"
+    aliases: ["Angular"]
+`,
+			wantTermMap: nil,
+			wantErr:     true,
+			errContains: "duplicate term found: Angular",
+		},
+		{
+			name: "empty alias",
+			yamlContent: `
+terms:
+  - term: "Kubernetes"
+    yomi: "クバネティス"
+    aliases: [""]
+`,
+			wantTermMap: nil,
+			wantErr:     true,
+			errContains: "empty alias for term Kubernetes",
+		},
+		{
+			name: "case-insensitive term conflicts with another case",
+			yamlContent: `
+terms:
+  - term: "Kubernetes"
+    yomi: "クバネティス"
+    case_sensitive: false
+  - term: "kubernetes"
+    yomi: "くばねてぃす"
+`,
+			wantTermMap: nil,
+			wantErr:     true,
+			errContains: "conflicts with",
+		},
 		{
 			name: "empty dictionary",
 			yamlContent: `
@@ -127,9 +186,196 @@ terms: []
 			if tt.wantErr && err != nil && !strings.Contains(err.Error(), tt.errContains) {
 				t.Errorf("LoadDictionary() error message = %q, want error message containing %q", err.Error(), tt.errContains)
 			}
-			if !tt.wantErr && !reflect.DeepEqual(got, tt.wantTermMap) {
-				t.Errorf("LoadDictionary() got = %v, want %v", got, tt.wantTermMap)
+			want := make(map[string]Term, len(tt.wantTermMap))
+			for spelling, term := range tt.wantTermMap {
+				term.Source = filePath // Every entry records the file it came from
+				want[spelling] = term
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadDictionary() got = %v, want %v", got, want)
 			}
 		})
 	}
 }
+
+func TestLoadDictionaries(t *testing.T) {
+	caseSensitive := false
+	tests := []struct {
+		name        string
+		layers      []string
+		want        map[string]Term // Source is the index of the layer, as a string
+		errContains string
+	}{
+		{
+			name: "later layers override terms",
+			layers: []string{
+				"terms:\n  - term: Go\n    yomi: ゴー\n  - term: Vite\n    yomi: ヴィート\n",
+				"terms:\n  - term: Go\n    yomi: ゴーラング\n",
+			},
+			want: map[string]Term{
+				"Go":   {Term: "Go", Yomi: "ゴーラング", Source: "1"},
+				"Vite": {Term: "Vite", Yomi: "ヴィート", Source: "0"},
+			},
+		},
+		{
+			name: "overriding a term drops its aliases",
+			layers: []string{
+				"terms:\n  - term: Kubernetes\n    yomi: クーベネティス\n    aliases: [K8s]\n",
+				"terms:\n  - term: Kubernetes\n    yomi: クバネティス\n",
+			},
+			want: map[string]Term{
+				"Kubernetes": {Term: "Kubernetes", Yomi: "クバネティス", Source: "1"},
+			},
+		},
+		{
+			name: "overriding an alias replaces only that alias",
+			layers: []string{
+				"terms:\n  - term: Kubernetes\n    yomi: クバネティス\n    aliases: [K8s]\n  - term: Go\n    yomi: ゴー\n",
+				"terms:\n  - term: K8s\n    yomi: ケーエイツ\n",
+			},
+			want: map[string]Term{
+				"Kubernetes": {Term: "Kubernetes", Yomi: "クバネティス", Aliases: []string{"K8s"}, Source: "0"},
+				"K8s":        {Term: "K8s", Yomi: "ケーエイツ", Source: "1"},
+				"Go":         {Term: "Go", Yomi: "ゴー", Source: "0"},
+			},
+		},
+		{
+			name: "a case-insensitive alias is replaced by another case",
+			layers: []string{
+				"terms:\n  - term: Kubernetes\n    yomi: クバネティス\n    aliases: [K8s]\n    case_sensitive: false\n",
+				"terms:\n  - term: k8s\n    yomi: けーえいつ\n",
+			},
+			want: map[string]Term{
+				"Kubernetes": {Term: "Kubernetes", Yomi: "クバネティス", Aliases: []string{"K8s"}, CaseSensitive: &caseSensitive, Source: "0"},
+				"k8s":        {Term: "k8s", Yomi: "けーえいつ", Source: "1"},
+			},
+		},
+		{
+			name: "overriding a case-insensitive spelling drops the whole entry",
+			layers: []string{
+				"terms:\n  - term: Kubernetes\n    yomi: クバネティス\n    aliases: [K8s]\n    case_sensitive: false\n",
+				"terms:\n  - term: kubernetes\n    yomi: くーばね\n",
+			},
+			want: map[string]Term{
+				"kubernetes": {Term: "kubernetes", Yomi: "くーばね", Source: "1"},
+			},
+		},
+		{
+			name: "a later term overrides a case-insensitive one",
+			layers: []string{
+				"terms:\n  - term: github\n    yomi: ぎっとはぶ\n    case_sensitive: false\n",
+				"terms:\n  - term: GitHub\n    yomi: ギットハブ\n",
+			},
+			want: map[string]Term{
+				"GitHub": {Term: "GitHub", Yomi: "ギットハブ", Source: "1"},
+			},
+		},
+		{
+			name: "a later case-insensitive term overrides other cases",
+			layers: []string{
+				"terms:\n  - term: github\n    yomi: ぎっとはぶ\n",
+				"terms:\n  - term: GitHub\n    yomi: ギットハブ\n    case_sensitive: false\n",
+			},
+			want: map[string]Term{
+				"GitHub": {Term: "GitHub", Yomi: "ギットハブ", CaseSensitive: &caseSensitive, Source: "1"},
+			},
+		},
+		{
+			name: "case-sensitive spellings are kept apart",
+			layers: []string{
+				"terms:\n  - term: github\n    yomi: ぎっとはぶ\n",
+				"terms:\n  - term: GitHub\n    yomi: ギットハブ\n",
+			},
+			want: map[string]Term{
+				"github": {Term: "github", Yomi: "ぎっとはぶ", Source: "0"},
+				"GitHub": {Term: "GitHub", Yomi: "ギットハブ", Source: "1"},
+			},
+		},
+		{
+			name: "duplicates within a file are still an error",
+			layers: []string{
+				"terms:\n  - term: Go\n    yomi: ゴー\n",
+				"terms:\n  - term: Vite\n    yomi: ヴィート\n  - term: Vite\n    yomi: バイト\n",
+			},
+			errContains: "duplicate term found: Vite",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			for _, layer := range tt.layers {
+				path := createTempDictFile(t, layer)
+				defer os.Remove(path)
+				paths = append(paths, path)
+			}
+
+			got, err := LoadDictionaries(paths)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("LoadDictionaries() error = %v, want error containing %q", err, tt.errContains)
+				}
+				if !strings.Contains(err.Error(), paths[len(paths)-1]) {
+					t.Errorf("LoadDictionaries() error = %v, want it to name the file", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadDictionaries() unexpected error: %v", err)
+			}
+
+			want := make(map[string]Term, len(tt.want))
+			for spelling, term := range tt.want {
+				i, _ := strconv.Atoi(term.Source)
+				term.Source = paths[i]
+				want[spelling] = term
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadDictionaries() got = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadDictionary_Directory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dictionary")
+	write := func(name, content string) string {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		writeTestFile(t, path, content)
+		return path
+	}
+	a := write("a.yaml", "terms:\n  - term: Angular\n    yomi: アンギュラー\n")
+	goFile := write("g/go.yml", "terms:\n  - term: Go\n    yomi: ゴー\n")
+	write(".drafts/v.yaml", "terms:\n  - term: Vite\n    yomi: ヴィート\n")
+	write("README.md", "Not a dictionary\n")
+
+	got, err := LoadDictionary(dir)
+	if err != nil {
+		t.Fatalf("LoadDictionary() unexpected error: %v", err)
+	}
+	want := map[string]Term{
+		"Angular": {Term: "Angular", Yomi: "アンギュラー", Source: a},
+		"Go":      {Term: "Go", Yomi: "ゴー", Source: goFile},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadDictionary() got = %v, want %v", got, want)
+	}
+
+	// Duplicates across files are reported with both locations
+	k8s := write("k/k8s.yaml", "terms:\n  - term: Kubernetes\n    yomi: クバネティス\n\n  - term: Golang\n    yomi: ゴー\n    aliases: [Go]\n")
+	_, err = LoadDictionary(dir)
+	wantErr := "duplicate term found: Go (" + goFile + ":2 and " + k8s + ":5)"
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("LoadDictionary() error = %v, want %q", err, wantErr)
+	}
+
+	if _, err := LoadDictionary(filepath.Join(dir, ".drafts", "empty")); err == nil {
+		t.Errorf("LoadDictionary() of a missing path should fail")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if _, err := LoadDictionary(filepath.Join(dir, "empty")); err == nil || !strings.Contains(err.Error(), "no dictionary files found") {
+		t.Errorf("LoadDictionary() error = %v, want error containing %q", err, "no dictionary files found")
+	}
+}
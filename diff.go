@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// lineChange replaces the original lines [From, To) with NewLines.
+type lineChange struct {
+	From     int
+	To       int
+	NewLines []string
+}
+
+// unifiedDiff renders patches against original as a unified diff for the file
+// called name, suitable for review or for "patch -p1" and "git apply".
+// It returns nil if there are no patches.
+func unifiedDiff(name string, original []byte, patches []Patch) []byte {
+	if len(patches) == 0 {
+		return nil
+	}
+	sorted := make([]Patch, len(patches))
+	copy(sorted, patches)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	oldLines := splitLines(original)
+	lineStarts := make([]int, len(oldLines)+1)
+	for i, line := range oldLines {
+		lineStarts[i+1] = lineStarts[i] + len(line)
+	}
+	lineOf := func(offset int) int {
+		// Index of the line containing offset; len(oldLines) for the end of a newline-terminated file
+		i := sort.Search(len(oldLines), func(i int) bool { return lineStarts[i+1] > offset })
+		if i == len(oldLines) && i > 0 && !strings.HasSuffix(oldLines[i-1], "\n") {
+			i-- // The end of a file without a final newline belongs to its last line
+		}
+		return i
+	}
+
+	// Group the patches into changes that cover whole lines
+	var changes []lineChange
+	var pending []Patch
+	flush := func(from, to int) {
+		chunkStart := lineStarts[from]
+		chunk := original[chunkStart:lineStarts[to]]
+		var buf bytes.Buffer
+		last := 0
+		for _, p := range pending {
+			buf.Write(chunk[last : p.Start-chunkStart])
+			buf.Write(p.NewText)
+			last = p.End - chunkStart
+		}
+		buf.Write(chunk[last:])
+		changes = append(changes, lineChange{From: from, To: to, NewLines: splitLines(buf.Bytes())})
+		pending = nil
+	}
+	from, to := 0, 0
+	for _, p := range sorted {
+		pFrom := lineOf(p.Start)
+		pTo := pFrom
+		if p.End > p.Start {
+			pTo = lineOf(p.End-1) + 1
+		} else if pFrom < len(oldLines) && p.Start > lineStarts[pFrom] {
+			pTo = pFrom + 1 // Insertion in the middle of a line
+		}
+		if len(pending) > 0 && pFrom > to {
+			flush(from, to)
+		}
+		if len(pending) == 0 {
+			from, to = pFrom, pTo
+		} else if pTo > to {
+			to = pTo
+		}
+		pending = append(pending, p)
+	}
+	flush(from, to)
+
+	var out bytes.Buffer
+	path := strings.TrimPrefix(filepath.ToSlash(name), "/")
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", path, path)
+
+	delta := 0 // Difference between new and old line numbers before the current hunk
+	for i := 0; i < len(changes); {
+		// Collect the changes whose context overlaps into one hunk
+		j := i + 1
+		for j < len(changes) && changes[j].From-changes[j-1].To <= 2*diffContext {
+			j++
+		}
+		hunk := changes[i:j]
+
+		oldStart := max(0, hunk[0].From-diffContext)
+		oldEnd := min(len(oldLines), hunk[len(hunk)-1].To+diffContext)
+		var body bytes.Buffer
+		newCount := 0
+		line := oldStart
+		for _, c := range hunk {
+			for ; line < c.From; line++ {
+				writeDiffLine(&body, ' ', oldLines[line])
+				newCount++
+			}
+			for ; line < c.To; line++ {
+				writeDiffLine(&body, '-', oldLines[line])
+			}
+			for _, newLine := range c.NewLines {
+				writeDiffLine(&body, '+', newLine)
+				newCount++
+			}
+		}
+		for ; line < oldEnd; line++ {
+			writeDiffLine(&body, ' ', oldLines[line])
+			newCount++
+		}
+
+		oldCount := oldEnd - oldStart
+		newStart := oldStart + delta
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		out.Write(body.Bytes())
+
+		for _, c := range hunk {
+			delta += len(c.NewLines) - (c.To - c.From)
+		}
+		i = j
+	}
+
+	return out.Bytes()
+}
+
+// hunkRange formats the 0-based start line and line count of a hunk side.
+// By convention an empty range refers to the line before it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// writeDiffLine writes a single diff line, marking a missing final newline.
+func writeDiffLine(buf *bytes.Buffer, prefix byte, line string) {
+	buf.WriteByte(prefix)
+	buf.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		buf.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// splitLines splits content into lines, keeping the trailing newline of each line.
+func splitLines(content []byte) []string {
+	var lines []string
+	for len(content) > 0 {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			lines = append(lines, string(content))
+			break
+		}
+		lines = append(lines, string(content[:i+1]))
+		content = content[i+1:]
+	}
+	return lines
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+)
+
+// --- Test unifiedDiff ---
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		patches  []Patch
+		want     string
+	}{
+		{
+			name:     "no patches",
+			original: "Vite\n",
+			patches:  nil,
+			want:     "",
+		},
+		{
+			name:     "single change with context",
+			original: "a\nb\nc\nVite\nd\ne\nf\ng\n",
+			patches:  []Patch{{Start: 6, End: 10, NewText: []byte("V")}},
+			want: "--- a/doc.md\n+++ b/doc.md\n" +
+				"@@ -1,7 +1,7 @@\n a\n b\n c\n-Vite\n+V\n d\n e\n f\n",
+		},
+		{
+			name:     "distant changes produce separate hunks",
+			original: "X\n1\n2\n3\n4\n5\n6\n7\nX\n",
+			patches: []Patch{
+				{Start: 16, End: 17, NewText: []byte("Y")},
+				{Start: 0, End: 1, NewText: []byte("Y")},
+			},
+			want: "--- a/doc.md\n+++ b/doc.md\n" +
+				"@@ -1,4 +1,4 @@\n-X\n+Y\n 1\n 2\n 3\n" +
+				"@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-X\n+Y\n",
+		},
+		{
+			name:     "two patches on one line",
+			original: "Go and Go\n",
+			patches: []Patch{
+				{Start: 0, End: 2, NewText: []byte("G")},
+				{Start: 7, End: 9, NewText: []byte("G")},
+			},
+			want: "--- a/doc.md\n+++ b/doc.md\n" +
+				"@@ -1,1 +1,1 @@\n-Go and Go\n+G and G\n",
+		},
+		{
+			name:     "missing newline at end of file",
+			original: "a\nVite",
+			patches:  []Patch{{Start: 2, End: 6, NewText: []byte("V")}},
+			want: "--- a/doc.md\n+++ b/doc.md\n" +
+				"@@ -1,2 +1,2 @@\n a\n-Vite\n\\ No newline at end of file\n+V\n\\ No newline at end of file\n",
+		},
+		{
+			name:     "lines appended at the end",
+			original: "a\n",
+			patches:  []Patch{{Start: 2, End: 2, NewText: []byte("\nb\n")}},
+			want: "--- a/doc.md\n+++ b/doc.md\n" +
+				"@@ -1,1 +1,3 @@\n a\n+\n+b\n",
+		},
+		{
+			name:     "lines appended to a file without a final newline",
+			original: "a",
+			patches:  []Patch{{Start: 1, End: 1, NewText: []byte("\nb\n")}},
+			want: "--- a/doc.md\n+++ b/doc.md\n" +
+				"@@ -1,1 +1,2 @@\n-a\n\\ No newline at end of file\n+a\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(unifiedDiff("doc.md", []byte(tt.original), tt.patches))
+			if got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
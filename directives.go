@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// directivePattern matches an HTML comment directive such as "<!-- rubi-disable -->"
+// or "<!-- rubi-ignore: Go, Vite -->".
+var directivePattern = regexp.MustCompile(`<!--\s*(rubi-[a-z-]+)(?:\s*:\s*(.*?))?\s*-->`)
+
+// Directives that suppress conversion.
+const (
+	directiveDisable         = "rubi-disable"           // Disable conversion until rubi-enable
+	directiveEnable          = "rubi-enable"            // Enable conversion again
+	directiveDisableNextLine = "rubi-disable-next-line" // Disable conversion on the following line
+	directiveIgnore          = "rubi-ignore"            // Do not convert the listed terms for the rest of the document
+)
+
+// ignoreDirective is a "rubi-ignore" directive.
+type ignoreDirective struct {
+	Offset int             // End of the directive, where it takes effect
+	Terms  map[string]bool // Terms as listed in the directive
+}
+
+// suppressions records where the directives in a document suppress conversion.
+type suppressions struct {
+	disabled [][2]int // Byte ranges where conversion is disabled, in document order
+	ignored  []ignoreDirective
+}
+
+// findSuppressions collects the directives in the HTML blocks and inline HTML of
+// the parsed document. Directives shown in code are ignored.
+func findSuppressions(document ast.Node, content []byte) *suppressions {
+	s := &suppressions{}
+	disabledFrom := -1
+
+	handle := func(value []byte, offset int) {
+		for _, m := range directivePattern.FindAllSubmatchIndex(value, -1) {
+			start, end := offset+m[0], offset+m[1]
+			switch string(value[m[2]:m[3]]) {
+			case directiveDisable:
+				if disabledFrom < 0 {
+					disabledFrom = end
+				}
+			case directiveEnable:
+				if disabledFrom >= 0 {
+					s.disabled = append(s.disabled, [2]int{disabledFrom, start})
+					disabledFrom = -1
+				}
+			case directiveDisableNextLine:
+				lineStart := len(content)
+				if i := bytes.IndexByte(content[end:], '\n'); i >= 0 {
+					lineStart = end + i + 1
+				}
+				lineEnd := len(content)
+				if i := bytes.IndexByte(content[lineStart:], '\n'); i >= 0 {
+					lineEnd = lineStart + i
+				}
+				s.disabled = append(s.disabled, [2]int{lineStart, lineEnd})
+			case directiveIgnore:
+				if m[4] < 0 {
+					continue
+				}
+				s.ignore(end, strings.Split(string(value[m[4]:m[5]]), ","))
+			}
+		}
+	}
+
+	ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindCodeBlock, ast.KindFencedCodeBlock, ast.KindCodeSpan:
+			return ast.WalkSkipChildren, nil
+		case ast.KindHTMLBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				handle(segment.Value(content), segment.Start)
+			}
+			return ast.WalkSkipChildren, nil
+		case ast.KindRawHTML:
+			segments := n.(*ast.RawHTML).Segments
+			for i := 0; i < segments.Len(); i++ {
+				segment := segments.At(i)
+				handle(segment.Value(content), segment.Start)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	if disabledFrom >= 0 {
+		s.disabled = append(s.disabled, [2]int{disabledFrom, len(content)})
+	}
+	return s
+}
+
+// ignore suppresses the given terms from offset to the end of the document.
+func (s *suppressions) ignore(offset int, terms []string) {
+	d := ignoreDirective{Offset: offset, Terms: make(map[string]bool)}
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			d.Terms[term] = true
+		}
+	}
+	if len(d.Terms) > 0 {
+		s.ignored = append(s.ignored, d)
+	}
+}
+
+// suppressed reports whether the directives prevent converting a term at offset.
+// terms are the spellings to check, e.g. the word as written and its dictionary entry.
+func (s *suppressions) suppressed(offset int, terms ...string) bool {
+	for _, r := range s.disabled {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	for _, d := range s.ignored {
+		if offset < d.Offset {
+			continue
+		}
+		for _, term := range terms {
+			if d.Terms[term] {
+				return true
+			}
+		}
+	}
+	return false
+}
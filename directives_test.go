@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+// --- Test suppression directives ---
+
+func TestProcess_Directives(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "scan - disable and enable",
+			opts:       Options{Scan: true},
+			input:      "Go\n\n<!-- rubi-disable -->\n\nGo and Vite\n\n<!-- rubi-enable -->\n\nVite\n",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby>\n\n<!-- rubi-disable -->\n\nGo and Vite\n\n<!-- rubi-enable -->\n\n<ruby>Vite<rt>ヴィート</rt></ruby>\n",
+		},
+		{
+			name:       "scan - disable without enable lasts until the end",
+			opts:       Options{Scan: true},
+			input:      "Go <!-- rubi-disable --> Go\n\nGo\n",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby> <!-- rubi-disable --> Go\n\nGo\n",
+		},
+		{
+			name:       "scan - disable next line",
+			opts:       Options{Scan: true},
+			input:      "<!-- rubi-disable-next-line -->\n# Go\n\nGo\n",
+			wantOutput: "<!-- rubi-disable-next-line -->\n# Go\n\n<ruby>Go<rt>ゴー</rt></ruby>\n",
+		},
+		{
+			name:       "scan - ignore terms from the directive on",
+			opts:       Options{Scan: true},
+			input:      "Go Vite gRPC\n\n<!-- rubi-ignore: Go, Vite -->\n\nGo Vite gRPC\n",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby> <ruby>Vite<rt>ヴィート</rt></ruby> <ruby>gRPC<rt>ジーアールピーシー</rt></ruby>\n\n<!-- rubi-ignore: Go, Vite -->\n\nGo Vite <ruby>gRPC<rt>ジーアールピーシー</rt></ruby>\n",
+		},
+		{
+			name:       "scan - first-only converts the first occurrence that is not disabled",
+			opts:       Options{Scan: true, FirstOnly: true},
+			input:      "<!-- rubi-disable-next-line -->\nGo\n\nGo and Go\n",
+			wantOutput: "<!-- rubi-disable-next-line -->\nGo\n\n<ruby>Go<rt>ゴー</rt></ruby> and Go\n",
+		},
+		{
+			name:       "manual - markers are left as written",
+			opts:       Options{},
+			input:      "<!-- rubi-disable -->\nWrite Vite:rubi to add ruby.\n<!-- rubi-enable -->\n\nVite:rubi\n",
+			wantOutput: "<!-- rubi-disable -->\nWrite Vite:rubi to add ruby.\n<!-- rubi-enable -->\n\n<ruby>Vite<rt>ヴィート</rt></ruby>\n",
+		},
+		{
+			name:       "directives in code are ignored",
+			opts:       Options{Scan: true},
+			input:      "`<!-- rubi-disable -->` Go\n",
+			wantOutput: "`<!-- rubi-disable -->` <ruby>Go<rt>ゴー</rt></ruby>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+		})
+	}
+}
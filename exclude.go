@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// newMarkdown returns the Markdown parser used for every document.
+// Tables are enabled so that table headers can be excluded with --exclude-nodes.
+func newMarkdown() goldmark.Markdown {
+	return goldmark.New(goldmark.WithExtensions(extension.Table))
+}
+
+// excludableNodes maps the names accepted by --exclude-nodes to the nodes they exclude.
+// Code and HTML are always excluded and cannot be listed here.
+var excludableNodes = map[string]func(n ast.Node) bool{
+	"heading":    func(n ast.Node) bool { return n.Kind() == ast.KindHeading },
+	"link":       func(n ast.Node) bool { return n.Kind() == ast.KindLink },
+	"image":      func(n ast.Node) bool { return n.Kind() == ast.KindImage },
+	"blockquote": func(n ast.Node) bool { return n.Kind() == ast.KindBlockquote },
+	"list":       func(n ast.Node) bool { return n.Kind() == ast.KindList },
+	"emphasis": func(n ast.Node) bool {
+		e, ok := n.(*ast.Emphasis)
+		return ok && e.Level == 1
+	},
+	"strong": func(n ast.Node) bool {
+		e, ok := n.(*ast.Emphasis)
+		return ok && e.Level == 2
+	},
+	"table":        func(n ast.Node) bool { return n.Kind() == east.KindTable },
+	"table-header": func(n ast.Node) bool { return n.Kind() == east.KindTableHeader },
+}
+
+// nodeFilter reports whether a node and everything inside it is excluded from conversion.
+type nodeFilter func(n ast.Node) bool
+
+// newNodeFilter returns a filter for the node kinds listed in names.
+func newNodeFilter(names []string) (nodeFilter, error) {
+	var excluded []func(n ast.Node) bool
+	for _, name := range names {
+		exclude, ok := excludableNodes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown node kind '%s' (expected %s)", name, strings.Join(excludableNodeNames(), ", "))
+		}
+		excluded = append(excluded, exclude)
+	}
+	return func(n ast.Node) bool {
+		for _, exclude := range excluded {
+			if exclude(n) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// excludableNodeNames returns the names accepted by --exclude-nodes in alphabetical order.
+func excludableNodeNames() []string {
+	names := make([]string, 0, len(excludableNodes))
+	for name := range excludableNodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseList splits a comma-separated flag value, such as that of --exclude-nodes
+// or --only-tags, into names.
+func parseList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
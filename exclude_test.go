@@ -0,0 +1,68 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// --- Test excluded node kinds ---
+
+func TestProcess_ExcludeNodes(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+	input := "# Go\n\n[Go](https://go.dev/) ![Go](go.png) *Go* **Go**\n\n> Go\n\n- Go\n\n| Go |\n| --- |\n| Go |\n\nGo\n"
+
+	tests := []struct {
+		exclude    []string
+		wantOutput string
+	}{
+		{
+			exclude:    nil,
+			wantOutput: "# G\n\n[G](https://go.dev/) ![G](go.png) *G* **G**\n\n> G\n\n- G\n\n| G |\n| --- |\n| G |\n\nG\n",
+		},
+		{
+			exclude:    []string{"heading", "link", "image"},
+			wantOutput: "# Go\n\n[Go](https://go.dev/) ![Go](go.png) *G* **G**\n\n> G\n\n- G\n\n| G |\n| --- |\n| G |\n\nG\n",
+		},
+		{
+			exclude:    []string{"emphasis", "blockquote", "table-header"},
+			wantOutput: "# G\n\n[G](https://go.dev/) ![G](go.png) *Go* **G**\n\n> Go\n\n- G\n\n| Go |\n| --- |\n| G |\n\nG\n",
+		},
+		{
+			exclude:    []string{"strong", "list", "table"},
+			wantOutput: "# G\n\n[G](https://go.dev/) ![G](go.png) *G* **Go**\n\n> G\n\n- Go\n\n| Go |\n| --- |\n| Go |\n\nG\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.exclude, ","), func(t *testing.T) {
+			result, err := processor.Process([]byte(input), Options{Scan: true, Quiet: true, ExcludeNodes: tt.exclude})
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			// "G" stands for the converted term to keep the expectations readable
+			got := strings.ReplaceAll(string(result.Content), "<ruby>Go<rt>ゴー</rt></ruby>", "G")
+			if got != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestNewNodeFilter_UnknownKind(t *testing.T) {
+	_, err := newNodeFilter([]string{"heading", "paragraph"})
+	if err == nil || !strings.Contains(err.Error(), "unknown node kind 'paragraph'") {
+		t.Errorf("newNodeFilter() error = %v, want unknown node kind error", err)
+	}
+}
+
+func TestParseNodeList(t *testing.T) {
+	got := parseList(" heading, link,,table-header ")
+	want := []string{"heading", "link", "table-header"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseList() = %v, want %v", got, want)
+	}
+	if got := parseList(""); got != nil {
+		t.Errorf("parseList(\"\") = %v, want nil", got)
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// markdownExtensions lists the file extensions picked up when walking a directory.
+var markdownExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+}
+
+// resolveInputs returns the files to process for the given inputs. The stdin
+// input "-" is passed through as is and cannot be combined with other inputs;
+// everything else is expanded with expandInputs and filtered with filterFiles.
+func resolveInputs(inputs, include, exclude []string) ([]string, error) {
+	for _, input := range inputs {
+		if input == stdinPath {
+			if len(inputs) > 1 {
+				return nil, fmt.Errorf("'-' (stdin) cannot be combined with other inputs")
+			}
+			return inputs, nil
+		}
+	}
+
+	files, err := expandInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Markdown files found in %s", strings.Join(inputs, ", "))
+	}
+	files, err = filterFiles(files, include, exclude)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("every Markdown file in %s is excluded by the include and exclude settings", strings.Join(inputs, ", "))
+	}
+	return files, nil
+}
+
+// expandInputs turns the command line arguments into a list of files to process.
+// An argument may be a file, a directory (walked recursively for Markdown files) or
+// a glob pattern. Patterns support "**" to match any number of directories, so
+// "content/**/*.md" works even when the shell does not expand it.
+// The result keeps the order of the arguments and contains no duplicates.
+func expandInputs(args []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(file string) {
+		file = filepath.Clean(file)
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			if err := expandPath(arg, add); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if strings.Contains(arg, "**") {
+			if err := globRecursive(arg, add); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%s': %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match '%s'", arg)
+		}
+		for _, match := range matches {
+			if err := expandPath(match, add); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// filterFiles keeps the files that match one of the include patterns (or all
+// files if there are none) and none of the exclude patterns. The patterns are
+// absolute and support "**" like the inputs. Files named on the command line are
+// filtered too, as the shell expands a pattern such as "content/**/*.md" into file names.
+func filterFiles(files, include, exclude []string) ([]string, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return files, nil
+	}
+	matchesAny := func(patterns []string, name []string) bool {
+		for _, pattern := range patterns {
+			if matchGlob(strings.Split(filepath.ToSlash(pattern), "/"), name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var kept []string
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve '%s': %w", file, err)
+		}
+		name := strings.Split(filepath.ToSlash(abs), "/")
+		if (len(include) == 0 || matchesAny(include, name)) && !matchesAny(exclude, name) {
+			kept = append(kept, file)
+		}
+	}
+	return kept, nil
+}
+
+// expandPath adds p itself if it is a file, or every Markdown file below it if it is a directory.
+// Hidden directories such as .git are skipped.
+func expandPath(p string, add func(string)) error {
+	info, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s': %w", p, err)
+	}
+	if !info.IsDir() {
+		add(p)
+		return nil
+	}
+
+	return filepath.WalkDir(p, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != p && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if markdownExtensions[strings.ToLower(filepath.Ext(file))] {
+			add(file)
+		}
+		return nil
+	})
+}
+
+// globRecursive adds every file matching a pattern that contains "**".
+func globRecursive(pattern string, add func(string)) error {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+
+	// Walk from the longest leading part of the pattern without wildcards
+	var base []string
+	for _, segment := range segments {
+		if strings.ContainsAny(segment, "*?[") {
+			break
+		}
+		base = append(base, segment)
+	}
+	root := "."
+	if len(base) > 0 {
+		root = filepath.FromSlash(strings.Join(base, "/"))
+		if root == "" {
+			root = "/"
+		}
+	}
+
+	found := false
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := strings.Split(filepath.ToSlash(filepath.Clean(file)), "/")
+		if matchGlob(segments, name) {
+			found = true
+			add(file)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to expand '%s': %w", pattern, err)
+	}
+	if !found {
+		return fmt.Errorf("no files match '%s'", pattern)
+	}
+	return nil
+}
+
+// matchGlob reports whether the path segments in name match the pattern segments.
+// A "**" segment matches zero or more path segments; other segments follow path.Match.
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// runParallel calls fn for every index in [0, n) using at most jobs goroutines
+// and waits for all of them to finish.
+func runParallel(n, jobs int, fn func(i int)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > n {
+		jobs = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// writeTestFiles creates the given files (relative to dir) with placeholder content.
+func writeTestFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		writeTestFile(t, filepath.Join(dir, filepath.FromSlash(name)), "Vite\n")
+	}
+}
+
+// writeTestFile writes content to path, creating its directory, and fails the test on any error.
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// --- Test expandInputs ---
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir,
+		"a.md",
+		"notes.txt",
+		"content/posts/b.md",
+		"content/posts/c.markdown",
+		"content/posts/deep/d.md",
+		"content/.hidden/e.md",
+	)
+	rel := func(names ...string) []string {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		want        []string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "single file",
+			args: rel("notes.txt"),
+			want: rel("notes.txt"),
+		},
+		{
+			name: "directory is walked for markdown files",
+			args: rel("content"),
+			want: rel("content/posts/b.md", "content/posts/c.markdown", "content/posts/deep/d.md"),
+		},
+		{
+			name: "simple glob",
+			args: []string{filepath.Join(dir, "*.md")},
+			want: rel("a.md"),
+		},
+		{
+			name: "recursive glob",
+			args: []string{filepath.Join(dir, "content", "**", "*.md")},
+			want: rel("content/posts/b.md", "content/posts/deep/d.md"),
+		},
+		{
+			name: "duplicates are removed",
+			args: append(rel("a.md", "content/posts/b.md"), filepath.Join(dir, "**", "b.md")),
+			want: rel("a.md", "content/posts/b.md"),
+		},
+		{
+			name:        "missing file",
+			args:        rel("missing.md"),
+			wantErr:     true,
+			errContains: "failed to read",
+		},
+		{
+			name:        "glob without matches",
+			args:        []string{filepath.Join(dir, "**", "*.rst")},
+			wantErr:     true,
+			errContains: "no files match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandInputs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandInputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expandInputs() error message = %q, want error message containing %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandInputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// --- Test matchGlob ---
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "content/**/*.md", name: "content/a.md", want: true},
+		{pattern: "content/**/*.md", name: "content/x/y/a.md", want: true},
+		{pattern: "content/**/*.md", name: "docs/a.md", want: false},
+		{pattern: "**/README.md", name: "README.md", want: true},
+		{pattern: "content/*.md", name: "content/x/a.md", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			got := matchGlob(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/"))
+			if got != tt.want {
+				t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// --- Test runParallel ---
+
+func TestRunParallel(t *testing.T) {
+	var running, maxRunning, calls int32
+	seen := make([]bool, 50)
+	runParallel(len(seen), 3, func(i int) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		seen[i] = true
+		atomic.AddInt32(&calls, 1)
+		atomic.AddInt32(&running, -1)
+	})
+
+	if calls != int32(len(seen)) {
+		t.Errorf("runParallel() made %d calls, want %d", calls, len(seen))
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Errorf("runParallel() did not call fn(%d)", i)
+		}
+	}
+	if maxRunning > 3 {
+		t.Errorf("runParallel() ran %d calls concurrently, want at most 3", maxRunning)
+	}
+}
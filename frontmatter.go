@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Front matter formats recognized at the start of a document.
+const (
+	frontMatterYAML = "yaml" // Delimited by "---" (closed by "---" or "...")
+	frontMatterTOML = "toml" // Delimited by "+++"
+)
+
+// frontMatter is the front matter block at the start of a document, as used by Hugo, Jekyll or Zenn.
+type frontMatter struct {
+	Format string
+	Body   []byte // Content between the delimiter lines
+	End    int    // Byte offset just past the closing delimiter line
+}
+
+// documentSettings are the per-document settings read from the "rubi" key of YAML front matter
+// or the [rubi] table of TOML front matter.
+// Settings that are not given keep the values from the command line.
+type documentSettings struct {
+	Scan           *bool    `yaml:"scan" toml:"scan"`
+	FirstOnly      *bool    `yaml:"firstOnly" toml:"firstOnly"`
+	FirstOnlyScope *string  `yaml:"firstOnlyScope" toml:"firstOnlyScope"`
+	OnlyTags       []string `yaml:"onlyTags" toml:"onlyTags"`
+	ExcludeTags    []string `yaml:"excludeTags" toml:"excludeTags"`
+	Exclude        []string `yaml:"exclude" toml:"exclude"` // Terms never converted in the document
+}
+
+// findFrontMatter returns the front matter at the start of content, or nil if there is none.
+// A block that does not parse as its format is plain Markdown (e.g. opening with a thematic break), not front matter.
+func findFrontMatter(content []byte) *frontMatter {
+	first, rest, ok := cutLine(content)
+	if !ok {
+		return nil
+	}
+	var format string
+	var closing []string
+	switch string(first) {
+	case "---":
+		format, closing = frontMatterYAML, []string{"---", "..."}
+	case "+++":
+		format, closing = frontMatterTOML, []string{"+++"}
+	default:
+		return nil
+	}
+
+	offset := len(content) - len(rest)
+	for len(rest) > 0 {
+		line, next, _ := cutLine(rest)
+		for _, delimiter := range closing {
+			if string(line) == delimiter {
+				fm := &frontMatter{Format: format, Body: content[offset : len(content)-len(rest)], End: len(content) - len(next)}
+				if !fm.parses() {
+					return nil
+				}
+				return fm
+			}
+		}
+		rest = next
+	}
+	return nil
+}
+
+// parses reports whether the body is a valid YAML mapping or TOML document. An empty body is valid.
+func (fm *frontMatter) parses() bool {
+	var data map[string]any
+	return fm.unmarshal(&data) == nil
+}
+
+// unmarshal decodes the body according to the front matter format.
+func (fm *frontMatter) unmarshal(v any) error {
+	if fm.Format == frontMatterTOML {
+		return toml.Unmarshal(fm.Body, v)
+	}
+	return yaml.Unmarshal(fm.Body, v)
+}
+
+// cutLine splits the first line, without its line ending and trailing spaces, from s.
+// It reports whether the line was terminated by a newline.
+func cutLine(s []byte) ([]byte, []byte, bool) {
+	line, rest, found := bytes.Cut(s, []byte("\n"))
+	return bytes.TrimRight(line, " \t\r"), rest, found
+}
+
+// mask returns a copy of content in which the front matter is blanked out with
+// spaces, keeping its line breaks so that offsets and line numbers stay the same.
+func (fm *frontMatter) mask(content []byte) []byte {
+	masked := bytes.Clone(content)
+	for i := 0; i < fm.End; i++ {
+		if masked[i] != '\n' {
+			masked[i] = ' '
+		}
+	}
+	return masked
+}
+
+// settings returns the rubi settings in the front matter.
+func (fm *frontMatter) settings() (documentSettings, error) {
+	var data struct {
+		Rubi documentSettings `yaml:"rubi" toml:"rubi"`
+	}
+	if err := fm.unmarshal(&data); err != nil {
+		return data.Rubi, fmt.Errorf("failed to parse front matter: %w", err)
+	}
+	return data.Rubi, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import "testing"
+
+// --- Test front matter ---
+
+func TestFindFrontMatter(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantFormat string
+		wantEnd    int
+	}{
+		{name: "yaml", input: "---\ntitle: Go\n---\nbody", wantFormat: frontMatterYAML, wantEnd: 18},
+		{name: "yaml closed by dots", input: "---\ntitle: Go\n...\n", wantFormat: frontMatterYAML, wantEnd: 18},
+		{name: "toml", input: "+++\ntitle = \"Go\"\n+++\n", wantFormat: frontMatterTOML, wantEnd: 21},
+		{name: "crlf", input: "---\r\ntitle: Go\r\n---\r\nbody", wantFormat: frontMatterYAML, wantEnd: 21},
+		{name: "unclosed", input: "---\ntitle: Go\n"},
+		{name: "not at the start", input: "\n---\ntitle: Go\n---\n"},
+		{name: "thematic break only", input: "---"},
+		{name: "thematic breaks around markdown", input: "---\n\nNote: Vite: is fast\n\n---\n"},
+		{name: "thematic breaks around a list", input: "---\n- Go\n---\n"},
+		{name: "plus signs around markdown", input: "+++\nGo is fast\n+++\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm := findFrontMatter([]byte(tt.input))
+			if tt.wantFormat == "" {
+				if fm != nil {
+					t.Errorf("findFrontMatter() = %+v, want nil", fm)
+				}
+				return
+			}
+			if fm == nil {
+				t.Fatalf("findFrontMatter() = nil, want %s front matter", tt.wantFormat)
+			}
+			if fm.Format != tt.wantFormat || fm.End != tt.wantEnd {
+				t.Errorf("findFrontMatter() = %s ending at %d, want %s ending at %d", fm.Format, fm.End, tt.wantFormat, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestProcess_FrontMatter(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name:       "yaml front matter is not converted",
+			opts:       Options{Scan: true},
+			input:      "---\ntitle: Go and Vite\ntags: [Go]\n---\n\nGo\n",
+			wantOutput: "---\ntitle: Go and Vite\ntags: [Go]\n---\n\n<ruby>Go<rt>ゴー</rt></ruby>\n",
+		},
+		{
+			name:       "toml front matter is not converted",
+			opts:       Options{Scan: true},
+			input:      "+++\ntitle = \"Go\"\n+++\nGo\n",
+			wantOutput: "+++\ntitle = \"Go\"\n+++\n<ruby>Go<rt>ゴー</rt></ruby>\n",
+		},
+		{
+			name:       "thematic breaks are not front matter",
+			opts:       Options{},
+			input:      "---\n\nNote: Vite: is fast\n\n---\n\nVite:rubi body\n",
+			wantOutput: "---\n\nNote: Vite: is fast\n\n---\n\n<ruby>Vite<rt>ヴィート</rt></ruby> body\n",
+		},
+		{
+			name:       "settings enable scan mode and first-only",
+			opts:       Options{},
+			input:      "---\nrubi:\n  scan: true\n  firstOnly: true\n---\nGo and Go\n",
+			wantOutput: "---\nrubi:\n  scan: true\n  firstOnly: true\n---\n<ruby>Go<rt>ゴー</rt></ruby> and Go\n",
+		},
+		{
+			name:       "settings disable scan mode",
+			opts:       Options{Scan: true},
+			input:      "---\nrubi: {scan: false}\n---\nGo and Vite:rubi\n",
+			wantOutput: "---\nrubi: {scan: false}\n---\nGo and <ruby>Vite<rt>ヴィート</rt></ruby>\n",
+		},
+		{
+			name:       "settings exclude terms",
+			opts:       Options{Scan: true},
+			input:      "---\nrubi: {exclude: [Go]}\n---\nGo and Vite\n",
+			wantOutput: "---\nrubi: {exclude: [Go]}\n---\nGo and <ruby>Vite<rt>ヴィート</rt></ruby>\n",
+		},
+		{
+			name:       "toml settings",
+			opts:       Options{},
+			input:      "+++\ntitle = \"Go\"\n\n[rubi]\nscan = true\nexclude = [\"Vite\"]\n+++\nGo and Vite\n",
+			wantOutput: "+++\ntitle = \"Go\"\n\n[rubi]\nscan = true\nexclude = [\"Vite\"]\n+++\n<ruby>Go<rt>ゴー</rt></ruby> and Vite\n",
+		},
+		{
+			name:    "invalid toml settings",
+			opts:    Options{},
+			input:   "+++\n[rubi]\nscan = \"maybe\"\n+++\nGo\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid settings",
+			opts:    Options{Scan: true},
+			input:   "---\nrubi: {scan: maybe}\n---\nGo\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+		})
+	}
+}
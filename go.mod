@@ -5,6 +5,7 @@ go 1.22
 toolchain go1.24.11
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/yuin/goldmark v1.7.13
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -4,36 +4,78 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 )
 
 // Config holds the application configuration
 type Config struct {
-	DictPath  string
-	Write     bool
-	Scan      bool // Reintroduced scan flag
-	FirstOnly bool // New first-only flag
-	Check     bool
-	DryRun    bool
-	InputFile string
+	DictPaths      []string // Dictionary files, later ones override earlier ones per term
+	Write          bool
+	Scan           bool   // Reintroduced scan flag
+	FirstOnly      bool   // New first-only flag
+	FirstOnlyScope string // Where --first-only starts over: page, section[:LEVEL] or paragraphs:N
+	Check          bool
+	DryRun         bool
+	Refresh        bool     // Update stale readings of existing ruby markup
+	Diff           bool     // Print a unified diff instead of the converted content
+	Format         string   // Output format: "text" or "json"
+	Renderer       string   // Name of the renderer used for the annotations
+	Ref            string   // How to show the reading source: none, title, link or footnote
+	Alternates     string   // How to show alternate readings: none, title or glossary
+	Glossary       bool     // Add a table of the dictionary terms in each document
+	ExcludeNodes   []string // Markdown node kinds whose text is never converted
+	OnlyTags       []string // In scan mode, convert only terms with one of these tags
+	ExcludeTags    []string // In scan mode, never convert terms with any of these tags
+	Jobs           int      // Number of files processed concurrently
+	Inputs         []string // Files, directories or glob patterns to process
+	ConfigPath     string   // Project configuration file to use instead of discovering .rubi.yaml
+	Include        []string // Absolute glob patterns of the files to process in directories and globs
+	Exclude        []string // Absolute glob patterns of the files to skip in directories and globs
 }
 
 // Global flags for the main command
 var (
-	mainFlagSet = flag.NewFlagSet("rubi", flag.ExitOnError)
-	dictPath    = mainFlagSet.String("d", "dict.yaml", "Dictionary file path")
-	write       = mainFlagSet.Bool("w", false, "Write back to the file")
-	scan        = mainFlagSet.Bool("s", false, "Scan mode")
-	firstOnly   = mainFlagSet.Bool("first-only", false, "Convert only the first occurrence of each term in scan mode")
-	check       = mainFlagSet.Bool("c", false, "Check dictionary validity")
-	dryRun      = mainFlagSet.Bool("dry-run", false, "Dry run mode")
+	mainFlagSet    = flag.NewFlagSet("rubi", flag.ExitOnError)
+	dictPaths      = newStringList(mainFlagSet, "d", "Dictionary file or directory, repeatable; later dictionaries override earlier ones (default "+defaultDictPath+")")
+	write          = mainFlagSet.Bool("w", false, "Write back to the file")
+	scan           = mainFlagSet.Bool("s", false, "Scan mode")
+	firstOnly      = mainFlagSet.Bool("first-only", false, "Convert only the first occurrence of each term in scan mode")
+	firstOnlyScope = mainFlagSet.String("first-only-scope", scopePage, "Where --first-only starts over: page, section, section:LEVEL or paragraphs:N")
+	check          = mainFlagSet.Bool("c", false, "Check dictionary validity")
+	dryRun         = mainFlagSet.Bool("dry-run", false, "Dry run mode")
+	refresh        = mainFlagSet.Bool("refresh", false, "Update the readings of existing ruby markup from the dictionary")
+	diff           = mainFlagSet.Bool("diff", false, "Print the changes as a unified diff instead of the converted content")
+	renderer       = mainFlagSet.String("renderer", defaultRenderer, "Annotation markup: "+strings.Join(rendererNames(), ", "))
+	ref            = mainFlagSet.String("ref", RefNone, "Show the reading source: none, title, link or footnote")
+	alternates     = mainFlagSet.String("alternates", AlternatesNone, "Show the alternate readings of terms: none, title or glossary (with --glossary)")
+	glossary       = mainFlagSet.Bool("glossary", false, "Add a table of the dictionary terms in the document (at <!-- rubi:glossary --> or at the end)")
+	excludeNodes   = mainFlagSet.String("exclude-nodes", "", "Comma-separated node kinds not to convert: "+strings.Join(excludableNodeNames(), ", "))
+	onlyTags       = mainFlagSet.String("only-tags", "", "Comma-separated tags; scan mode converts only terms with one of them")
+	excludeTags    = mainFlagSet.String("exclude-tags", "", "Comma-separated tags; scan mode never converts terms with any of them")
+	format         = mainFlagSet.String("format", formatText, "Output format: text or json (a report of every conversion)")
+	jobs           = mainFlagSet.Int("j", runtime.NumCPU(), "Number of files to process concurrently")
+	configPath     = mainFlagSet.String("config", "", "Project configuration file (default: "+projectConfigName+" found from the input upwards)")
 )
 
 // cmdRunner is a package-level variable that can be overridden for testing.
 var cmdRunner = exec.Command
 
+// stdinPath is the input name that makes rubi read Markdown from stdin and write the result to stdout.
+const stdinPath = "-"
+
+// stdin and stdinIsPipe are package-level variables that can be overridden for testing.
+var (
+	stdin       io.Reader = os.Stdin
+	stdinIsPipe           = func() bool {
+		info, err := os.Stdin.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice == 0
+	}
+)
+
 // Subcommand flag sets
 var (
 	initFlagSet   = flag.NewFlagSet("init", flag.ExitOnError)
@@ -42,6 +84,30 @@ var (
 
 	dictUpdateFlagSet = flag.NewFlagSet("dict update", flag.ExitOnError) // FlagSet for 'dict update'
 	dictUpdateRepo    = dictUpdateFlagSet.String("repo", "takaryo1010/rubi", "GitHub repository to download dict.yaml from (e.g., owner/repo)")
+
+	checkFlagSet        = flag.NewFlagSet("check", flag.ExitOnError)
+	checkDictPaths      = newStringList(checkFlagSet, "d", "Dictionary file or directory, repeatable; later dictionaries override earlier ones (default "+defaultDictPath+")")
+	checkScan           = checkFlagSet.Bool("s", false, "Scan mode")
+	checkFirstOnly      = checkFlagSet.Bool("first-only", false, "Convert only the first occurrence of each term in scan mode")
+	checkFirstOnlyScope = checkFlagSet.String("first-only-scope", scopePage, "Where --first-only starts over: page, section, section:LEVEL or paragraphs:N")
+	checkRefresh        = checkFlagSet.Bool("refresh", false, "Also report existing ruby markup whose reading differs from the dictionary")
+	checkRenderer       = checkFlagSet.String("renderer", defaultRenderer, "Annotation markup the documents are converted with: "+strings.Join(rendererNames(), ", "))
+	checkRef            = checkFlagSet.String("ref", RefNone, "Show the reading source: none, title, link or footnote")
+	checkAlternates     = checkFlagSet.String("alternates", AlternatesNone, "Show the alternate readings of terms: none, title or glossary (with --glossary)")
+	checkGlossary       = checkFlagSet.Bool("glossary", false, "Also check the table of the dictionary terms in the document")
+	checkExcludeNodes   = checkFlagSet.String("exclude-nodes", "", "Comma-separated node kinds not to convert: "+strings.Join(excludableNodeNames(), ", "))
+	checkOnlyTags       = checkFlagSet.String("only-tags", "", "Comma-separated tags; scan mode converts only terms with one of them")
+	checkExcludeTags    = checkFlagSet.String("exclude-tags", "", "Comma-separated tags; scan mode never converts terms with any of them")
+	checkJobs           = checkFlagSet.Int("j", runtime.NumCPU(), "Number of files to check concurrently")
+	checkConfigPath     = checkFlagSet.String("config", "", "Project configuration file (default: "+projectConfigName+" found from the input upwards)")
+
+	stripFlagSet        = flag.NewFlagSet("strip", flag.ExitOnError)
+	stripDictPaths      = newStringList(stripFlagSet, "d", "Dictionary file or directory used with --restore-markers, repeatable (default "+defaultDictPath+")")
+	stripWrite          = stripFlagSet.Bool("w", false, "Write back to the file")
+	stripDiff           = stripFlagSet.Bool("diff", false, "Print the changes as a unified diff instead of the stripped content")
+	stripRestoreMarkers = stripFlagSet.Bool("restore-markers", false, "Turn ruby markup back into word:rubi markers instead of plain text")
+	stripJobs           = stripFlagSet.Int("j", runtime.NumCPU(), "Number of files to process concurrently")
+	stripConfigPath     = stripFlagSet.String("config", "", "Project configuration file (default: "+projectConfigName+" found from the input upwards)")
 )
 
 func main() {
@@ -55,9 +121,12 @@ func runCLI() error {
 	// Custom usage function for the main command
 	mainFlagSet.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  %s [options] <input_file>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s [options] <file|directory|glob>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s [options] - (read from stdin, write to stdout)\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s <command> [options]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  check       Check documents for unconverted or unknown terms\n")
+		fmt.Fprintf(os.Stderr, "  strip       Remove ruby markup generated by rubi\n")
 		fmt.Fprintf(os.Stderr, "  init        Initialize a dict.yaml from GitHub\n")
 		fmt.Fprintf(os.Stderr, "  dict update Update dict.yaml from GitHub\n") // Updated usage
 		fmt.Fprintf(os.Stderr, "Options for main command:\n")
@@ -66,7 +135,7 @@ func runCLI() error {
 
 	// Determine subcommand or main command
 	args := os.Args[1:]
-	if len(args) == 0 { // No arguments, show main usage
+	if len(args) == 0 && !stdinIsPipe() { // No arguments and nothing piped in, show main usage
 		mainFlagSet.Usage()
 		return nil // Exit cleanly after showing usage
 	}
@@ -74,9 +143,9 @@ func runCLI() error {
 	// Check if the first non-flag argument is a known subcommand
 	// We need to peek ahead for subcommands because mainFlagSet might consume it.
 	subcommand := ""
-	if !strings.HasPrefix(args[0], "-") { // If first arg is not a flag, it might be a subcommand
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") { // If first arg is not a flag, it might be a subcommand
 		switch args[0] {
-		case "init", "dict", "help":
+		case "init", "dict", "check", "strip", "help":
 			subcommand = args[0]
 		}
 	}
@@ -92,6 +161,53 @@ func runCLI() error {
 			}
 			initFlagSet.Parse(args[1:])
 			return handleInitCommand(*initRepo, *initOverwrite)
+		case "check":
+			checkFlagSet.Usage = func() {
+				fmt.Fprintf(os.Stderr, "Usage of %s check:\n", os.Args[0])
+				fmt.Fprintf(os.Stderr, "  %s check [options] <file|directory|glob>...\n", os.Args[0])
+				checkFlagSet.PrintDefaults()
+			}
+			checkFlagSet.Parse(args[1:])
+			cfg := &Config{
+				DictPaths:      checkDictPaths.valuesOr(defaultDictPath),
+				Scan:           *checkScan,
+				FirstOnly:      *checkFirstOnly,
+				FirstOnlyScope: *checkFirstOnlyScope,
+				Refresh:        *checkRefresh,
+				Renderer:       *checkRenderer,
+				Ref:            *checkRef,
+				Alternates:     *checkAlternates,
+				Glossary:       *checkGlossary,
+				ExcludeNodes:   parseList(*checkExcludeNodes),
+				OnlyTags:       parseList(*checkOnlyTags),
+				ExcludeTags:    parseList(*checkExcludeTags),
+				Jobs:           *checkJobs,
+				Inputs:         checkFlagSet.Args(),
+				ConfigPath:     *checkConfigPath,
+			}
+			if err := applyProjectConfig(cfg, checkFlagSet); err != nil {
+				return err
+			}
+			return handleCheckCommand(cfg)
+		case "strip":
+			stripFlagSet.Usage = func() {
+				fmt.Fprintf(os.Stderr, "Usage of %s strip:\n", os.Args[0])
+				fmt.Fprintf(os.Stderr, "  %s strip [options] <file|directory|glob>...\n", os.Args[0])
+				stripFlagSet.PrintDefaults()
+			}
+			stripFlagSet.Parse(args[1:])
+			cfg := &Config{
+				DictPaths:  stripDictPaths.valuesOr(defaultDictPath),
+				Write:      *stripWrite,
+				Diff:       *stripDiff,
+				Jobs:       *stripJobs,
+				Inputs:     stripFlagSet.Args(),
+				ConfigPath: *stripConfigPath,
+			}
+			if err := applyProjectConfig(cfg, stripFlagSet); err != nil {
+				return err
+			}
+			return handleStripCommand(cfg, *stripRestoreMarkers)
 		case "dict":
 			if len(args) < 2 {
 				return fmt.Errorf("missing subcommand for 'dict'\n\nUsage: %s dict <command> [options]\nCommands:\n  update", os.Args[0])
@@ -118,32 +234,51 @@ func runCLI() error {
 		// No subcommand found, treat all arguments as belonging to the main command
 		mainFlagSet.Parse(args)
 		cfg := &Config{
-			DictPath:  *dictPath,
-			Write:     *write,
-			Scan:      *scan,
-			FirstOnly: *firstOnly,
-			Check:     *check,
-			DryRun:    *dryRun,
+			DictPaths:      dictPaths.valuesOr(defaultDictPath),
+			Write:          *write,
+			Scan:           *scan,
+			FirstOnly:      *firstOnly,
+			FirstOnlyScope: *firstOnlyScope,
+			Check:          *check,
+			DryRun:         *dryRun,
+			Refresh:        *refresh,
+			Diff:           *diff,
+			Format:         *format,
+			Renderer:       *renderer,
+			Ref:            *ref,
+			Alternates:     *alternates,
+			Glossary:       *glossary,
+			ExcludeNodes:   parseList(*excludeNodes),
+			OnlyTags:       parseList(*onlyTags),
+			ExcludeTags:    parseList(*excludeTags),
+			Jobs:           *jobs,
+			Inputs:         mainFlagSet.Args(),
+			ConfigPath:     *configPath,
 		}
-		if mainFlagSet.NArg() > 0 {
-			cfg.InputFile = mainFlagSet.Arg(0)
+		if err := applyProjectConfig(cfg, mainFlagSet); err != nil {
+			return err
 		}
 		return handleMainCommand(cfg)
 	}
 }
 
 func handleMainCommand(cfg *Config) error {
+	// Without an input file, act as a filter when Markdown is piped in
+	if !cfg.Check && len(cfg.Inputs) == 0 && stdinIsPipe() {
+		cfg.Inputs = []string{stdinPath}
+	}
+
 	// Logic for flag validation (from previous iteration)
 	if cfg.Check {
-		if cfg.InputFile != "" {
+		if len(cfg.Inputs) > 0 {
 			mainFlagSet.Usage()
 			return fmt.Errorf("the -c flag cannot be used with an input file")
 		}
-		if cfg.Scan || cfg.FirstOnly || cfg.Write || cfg.DryRun {
-			return fmt.Errorf("the -c flag cannot be used with other processing flags (-s, --first-only, -w, --dry-run)")
+		if cfg.Scan || cfg.FirstOnly || cfg.Write || cfg.DryRun || cfg.Diff || cfg.Refresh {
+			return fmt.Errorf("the -c flag cannot be used with other processing flags (-s, --first-only, -w, --dry-run, --diff, --refresh)")
 		}
 	} else if cfg.Scan { // Scan mode validation
-		if cfg.InputFile == "" {
+		if len(cfg.Inputs) == 0 {
 			mainFlagSet.Usage()
 			return fmt.Errorf("an input file is required for scan mode (-s)")
 		}
@@ -151,48 +286,238 @@ func handleMainCommand(cfg *Config) error {
 		if cfg.FirstOnly {
 			return fmt.Errorf("the --first-only flag is only valid in -s (scan) mode")
 		}
-		if cfg.InputFile == "" {
+		if len(cfg.OnlyTags) > 0 || len(cfg.ExcludeTags) > 0 {
+			return fmt.Errorf("the --only-tags and --exclude-tags flags are only valid in -s (scan) mode")
+		}
+		if len(cfg.Inputs) == 0 {
 			mainFlagSet.Usage()
 			return fmt.Errorf("an input file is required for manual mode")
 		}
 	}
 
+	if cfg.Diff && (cfg.Write || cfg.DryRun) {
+		return fmt.Errorf("the --diff flag cannot be used with -w or --dry-run")
+	}
+	switch cfg.Format {
+	case "", formatText:
+	case formatJSON:
+		if cfg.Diff || cfg.DryRun {
+			return fmt.Errorf("--format json cannot be used with --diff or --dry-run")
+		}
+	default:
+		return fmt.Errorf("unknown output format '%s' (expected text or json)", cfg.Format)
+	}
+
+	if _, err := newRenderer(cfg.Renderer, cfg.Ref, cfg.Alternates); err != nil {
+		return err
+	}
+	if _, err := newNodeFilter(cfg.ExcludeNodes); err != nil {
+		return err
+	}
+	if err := validateFirstOnlyScope(cfg); err != nil {
+		return err
+	}
+
 	// Handle --check mode
 	if cfg.Check {
-		return validateDictionary(cfg.DictPath)
+		return validateDictionaries(cfg.DictPaths)
 	}
 
-	// Load the dictionary
-	termMap, err := LoadDictionary(cfg.DictPath)
+	// Load the dictionary once for all files
+	termMap, err := LoadDictionaries(cfg.DictPaths)
 	if err != nil {
 		return err
 	}
+	if err := validateTags(termMap, cfg.OnlyTags, cfg.ExcludeTags); err != nil {
+		return err
+	}
+	processor := NewProcessor(termMap)
+	opts := Options{
+		DryRun:         cfg.DryRun,
+		Scan:           cfg.Scan,
+		FirstOnly:      cfg.FirstOnly,
+		FirstOnlyScope: cfg.FirstOnlyScope,
+		Quiet:          cfg.Format == formatJSON,
+		Refresh:        cfg.Refresh,
+		Renderer:       cfg.Renderer,
+		Ref:            cfg.Ref,
+		Alternates:     cfg.Alternates,
+		Glossary:       cfg.Glossary,
+		ExcludeNodes:   cfg.ExcludeNodes,
+		OnlyTags:       cfg.OnlyTags,
+		ExcludeTags:    cfg.ExcludeTags,
+	}
+
+	return convertInputs(cfg, func(content []byte) (*Result, error) {
+		return processor.Process(content, opts)
+	})
+}
 
-	// Read the specified file
-	content, err := os.ReadFile(cfg.InputFile)
+// handleStripCommand removes the ruby markup generated by rubi from the inputs of cfg.
+// With restoreMarkers the markup becomes word:rubi markers again, using the
+// dictionary to decide whether an inline reading has to be kept.
+func handleStripCommand(cfg *Config, restoreMarkers bool) error {
+	if len(cfg.Inputs) == 0 && stdinIsPipe() {
+		cfg.Inputs = []string{stdinPath}
+	}
+	if len(cfg.Inputs) == 0 {
+		stripFlagSet.Usage()
+		return fmt.Errorf("an input file is required for strip")
+	}
+	if cfg.Diff && cfg.Write {
+		return fmt.Errorf("the --diff flag cannot be used with -w")
+	}
+
+	var termMap map[string]Term
+	if restoreMarkers {
+		var err error
+		termMap, err = LoadDictionaries(cfg.DictPaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	return convertInputs(cfg, func(content []byte) (*Result, error) {
+		return StripRuby(content, restoreMarkers, termMap)
+	})
+}
+
+// validateFirstOnlyScope checks the --first-only-scope value of cfg.
+func validateFirstOnlyScope(cfg *Config) error {
+	scope, err := parseFirstOnlyScope(cfg.FirstOnlyScope)
 	if err != nil {
-		return fmt.Errorf("failed to read file '%s': %w", cfg.InputFile, err)
+		return err
+	}
+	if scope.Kind != scopePage && !cfg.FirstOnly {
+		return fmt.Errorf("the --first-only-scope flag requires --first-only")
 	}
+	return nil
+}
+
+// converter turns the content of one document into a Result.
+type converter func(content []byte) (*Result, error)
 
-	// Process the Markdown content
-	processedContent, err := ProcessMarkdown(content, cfg.DryRun, cfg.Scan, cfg.FirstOnly, termMap)
+// convertInputs runs convert over every input of cfg and outputs the results
+// according to the -w, --diff, --format and --dry-run settings.
+func convertInputs(cfg *Config, convert converter) error {
+	files, err := resolveInputs(cfg.Inputs, cfg.Include, cfg.Exclude)
 	if err != nil {
-		return fmt.Errorf("failed to process markdown: %w", err)
+		return err
+	}
+	if cfg.Write && files[0] == stdinPath {
+		return fmt.Errorf("the -w flag cannot be used when reading from stdin")
 	}
 
-	// Output the content
-	if cfg.Write {
-		if err := os.WriteFile(cfg.InputFile, processedContent, 0644); err != nil {
-			return fmt.Errorf("failed to write to file '%s': %w", cfg.InputFile, err)
+	// A single file without -w keeps the classic behaviour of printing the result
+	if len(files) == 1 && !cfg.Write && !cfg.Diff && cfg.Format != formatJSON {
+		_, result, err := processFile(files[0], convert)
+		if err != nil {
+			return err
 		}
-		fmt.Printf("File '%s' has been updated.\n", cfg.InputFile)
-	} else {
-		fmt.Print(string(processedContent))
+		fmt.Print(string(result.Content))
+		return nil
+	}
+	if !cfg.Write && !cfg.DryRun && !cfg.Diff && cfg.Format != formatJSON {
+		return fmt.Errorf("processing multiple files requires -w, --diff, --format json or --dry-run")
+	}
+
+	return processFiles(cfg, files, convert)
+}
+
+// fileResult is the outcome of processing one file in processFiles.
+type fileResult struct {
+	Original []byte
+	Result   *Result
+	Changed  bool
+	Err      error
+}
+
+// processFiles converts files concurrently with a bounded worker pool, prints a
+// per-file summary (or diff) in input order and returns an error if any file failed.
+func processFiles(cfg *Config, files []string, convert converter) error {
+	jobs := cfg.Jobs
+	if cfg.DryRun {
+		jobs = 1 // Keep the dry-run log of each file together
 	}
 
+	results := make([]fileResult, len(files))
+	runParallel(len(files), jobs, func(i int) {
+		if cfg.DryRun {
+			fmt.Fprintf(os.Stderr, "==> %s <==\n", files[i])
+		}
+		original, result, err := processFile(files[i], convert)
+		changed := err == nil && !bytes.Equal(original, result.Content)
+		if changed && cfg.Write && !cfg.DryRun {
+			if writeErr := os.WriteFile(files[i], result.Content, 0644); writeErr != nil {
+				err = fmt.Errorf("failed to write to file '%s': %w", files[i], writeErr)
+			}
+		}
+		results[i] = fileResult{Original: original, Result: result, Changed: changed, Err: err}
+	})
+
+	if cfg.Format == formatJSON {
+		if err := writeJSONReport(os.Stdout, files, results); err != nil {
+			return fmt.Errorf("failed to write JSON report: %w", err)
+		}
+	}
+
+	failed := 0
+	for i, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "Error: %v\n", result.Err)
+		case cfg.Format == formatJSON:
+			// The report has already been printed
+		case cfg.Diff:
+			os.Stdout.Write(unifiedDiff(displayName(files[i]), result.Original, result.Result.Patches))
+		case cfg.DryRun:
+			// The dry-run log has already been printed
+		case result.Changed:
+			fmt.Printf("File '%s' has been updated.\n", files[i])
+		default:
+			fmt.Printf("File '%s' is unchanged.\n", files[i])
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(files))
+	}
 	return nil
 }
 
+// processFile reads and converts a single file, or stdin if path is "-".
+// It returns the original content along with the processing result.
+func processFile(path string, convert converter) ([]byte, *Result, error) {
+	content, err := readInput(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := convert(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process markdown in '%s': %w", path, err)
+	}
+
+	return content, result, nil
+}
+
+// readInput reads the named file, or stdin if path is "-".
+func readInput(path string) ([]byte, error) {
+	if path == stdinPath {
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return content, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file '%s': %w", path, err)
+	}
+	return content, nil
+}
+
 func handleInitCommand(repo string, overwrite bool) error {
 	fmt.Printf("Initializing dict.yaml from %s...\n", repo)
 	filePath := "dict.yaml"
@@ -248,12 +573,37 @@ func downloadDictFile(repo, filePath string) error {
 	return nil
 }
 
-// validateDictionary performs validation on the dictionary file.
-func validateDictionary(path string) error {
-	_, err := LoadDictionary(path)
+// validateDictionaries performs validation on the dictionaries (files or directories)
+// and reports, for every dictionary after the first, the terms that override earlier ones.
+func validateDictionaries(paths []string) error {
+	termMap, layers, err := loadDictionaryLayers(paths)
 	if err != nil {
 		return fmt.Errorf("dictionary validation failed: %w", err)
 	}
-	fmt.Printf("Dictionary at '%s' is valid.\n", path)
+
+	for _, layer := range layers {
+		var details []string
+		if len(layer.Files) != 1 || !layer.Files[layer.Path] { // A directory
+			details = append(details, fmt.Sprintf("%d files", len(layer.Files)))
+		}
+		if len(layers) > 1 {
+			inUse := make(map[string]bool) // Entries of the layer still reachable by any spelling
+			for _, term := range termMap {
+				if layer.Files[term.Source] {
+					inUse[entryKey(term)] = true
+				}
+			}
+			details = append(details, fmt.Sprintf("%d terms in use", len(inUse)), fmt.Sprintf("%d earlier spellings overridden", len(layer.Overrides)))
+		}
+
+		if len(details) > 0 {
+			fmt.Printf("Dictionary at '%s' is valid (%s).\n", layer.Path, strings.Join(details, ", "))
+		} else {
+			fmt.Printf("Dictionary at '%s' is valid.\n", layer.Path)
+		}
+		for _, o := range layer.Overrides {
+			fmt.Printf("  overrides '%s' from '%s'\n", o.Spelling, o.Previous.Source)
+		}
+	}
 	return nil
 }
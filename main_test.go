@@ -1,6 +1,7 @@
 package main
 
 import (
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -348,3 +349,215 @@ func TestDownloadDictFile_WriteFileError(t *testing.T) {
 		t.Errorf("downloadDictFile() error message = %q, want error message containing \"failed to write dict.yaml\"", err.Error())
 	}
 }
+
+// --- Test handleMainCommand ---
+
+func TestHandleMainCommand_MultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+	dictFile := filepath.Join(dir, "dict.yaml")
+	writeTestFile(t, dictFile, testDictYAML)
+	writeTestFiles(t, dir, "docs/a.md", "docs/sub/b.md")
+	writeTestFile(t, filepath.Join(dir, "docs", "plain.md"), "Nothing here\n")
+
+	cfg := &Config{DictPaths: []string{dictFile}, Write: true, Scan: true, Jobs: 2, Inputs: []string{filepath.Join(dir, "docs")}}
+	if err := handleMainCommand(cfg); err != nil {
+		t.Fatalf("handleMainCommand() unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"docs/a.md", "docs/sub/b.md"} {
+		content, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if want := "<ruby>Vite<rt>ヴィート</rt></ruby>\n"; string(content) != want {
+			t.Errorf("%s content = %q, want %q", name, content, want)
+		}
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "docs", "plain.md"))
+	if string(content) != "Nothing here\n" {
+		t.Errorf("plain.md content = %q, want it unchanged", content)
+	}
+}
+
+func TestHandleMainCommand_MultipleFilesErrors(t *testing.T) {
+	dir := t.TempDir()
+	dictFile := filepath.Join(dir, "dict.yaml")
+	writeTestFile(t, dictFile, testDictYAML)
+	writeTestFiles(t, dir, "a.md", "b.md")
+
+	// Without -w there is nowhere to put the output of several files
+	cfg := &Config{DictPaths: []string{dictFile}, Scan: true, Jobs: 1, Inputs: []string{filepath.Join(dir, "*.md")}}
+	if err := handleMainCommand(cfg); err == nil || !strings.Contains(err.Error(), "requires -w") {
+		t.Errorf("handleMainCommand() error = %v, want error containing %q", err, "requires -w")
+	}
+
+	// A missing input is reported before anything is written
+	cfg = &Config{DictPaths: []string{dictFile}, Write: true, Scan: true, Jobs: 2, Inputs: []string{filepath.Join(dir, "a.md"), filepath.Join(dir, "missing.md")}}
+	if err := handleMainCommand(cfg); err == nil || !strings.Contains(err.Error(), "failed to read") {
+		t.Errorf("handleMainCommand() error = %v, want error containing %q", err, "failed to read")
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.md")); string(content) != "Vite\n" {
+		t.Errorf("a.md content = %q, want it unchanged", content)
+	}
+}
+
+// captureStdout runs fn and returns what it wrote to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+	fn()
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestHandleMainCommand_Stdin(t *testing.T) {
+	dictFile := filepath.Join(t.TempDir(), "dict.yaml")
+	writeTestFile(t, dictFile, testDictYAML)
+
+	oldStdin, oldStdinIsPipe := stdin, stdinIsPipe
+	defer func() { stdin, stdinIsPipe = oldStdin, oldStdinIsPipe }()
+	stdinIsPipe = func() bool { return true }
+
+	tests := []struct {
+		name        string
+		cfg         *Config
+		wantOutput  string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:       "explicit dash",
+			cfg:        &Config{DictPaths: []string{dictFile}, Scan: true, Inputs: []string{"-"}},
+			wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby> is fast.\n",
+		},
+		{
+			name:       "piped stdin without an input file",
+			cfg:        &Config{DictPaths: []string{dictFile}},
+			wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby> is fast.\n",
+		},
+		{
+			name:        "cannot write back to stdin",
+			cfg:         &Config{DictPaths: []string{dictFile}, Scan: true, Write: true, Inputs: []string{"-"}},
+			wantErr:     true,
+			errContains: "cannot be used when reading from stdin",
+		},
+		{
+			name:        "dash mixed with files",
+			cfg:         &Config{DictPaths: []string{dictFile}, Scan: true, Inputs: []string{"-", "a.md"}},
+			wantErr:     true,
+			errContains: "cannot be combined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "Vite is fast.\n"
+			if !tt.cfg.Scan {
+				input = "Vite:rubi is fast.\n"
+			}
+			stdin = strings.NewReader(input)
+
+			var err error
+			got := captureStdout(t, func() { err = handleMainCommand(tt.cfg) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handleMainCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("handleMainCommand() error message = %q, want error message containing %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if got != tt.wantOutput {
+				t.Errorf("handleMainCommand() stdout = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestRunCLI_Stdin(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFile(t, filepath.Join(tmpDir, "dict.yaml"), testDictYAML)
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get current working directory: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change to temp directory: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(originalWd); err != nil {
+			t.Fatalf("failed to restore working directory: %v", err)
+		}
+	}()
+
+	oldArgs, oldStdin, oldStdinIsPipe := os.Args, stdin, stdinIsPipe
+	defer func() { os.Args, stdin, stdinIsPipe = oldArgs, oldStdin, oldStdinIsPipe }()
+	os.Args = []string{"rubi"}
+	stdin = strings.NewReader("Vite:rubi ok\n")
+	stdinIsPipe = func() bool { return true }
+
+	got := captureStdout(t, func() { err = runCLI() })
+	if err != nil {
+		t.Fatalf("runCLI() unexpected error: %v", err)
+	}
+	if want := "<ruby>Vite<rt>ヴィート</rt></ruby> ok\n"; got != want {
+		t.Errorf("runCLI() stdout = %q, want %q", got, want)
+	}
+}
+
+func TestValidateDictionaries(t *testing.T) {
+	dir := t.TempDir()
+	community := filepath.Join(dir, "dict.yaml")
+	company := filepath.Join(dir, "company.yaml")
+	writeTestFile(t, community, "terms:\n  - term: Go\n    yomi: ゴー\n  - term: Vite\n    yomi: ヴィート\n")
+	writeTestFile(t, company, "terms:\n  - term: Go\n    yomi: ゴーラング\n  - term: Nuxt\n    yomi: ナクスト\n")
+
+	var err error
+	out := captureStdout(t, func() { err = validateDictionaries([]string{community, company}) })
+	if err != nil {
+		t.Fatalf("validateDictionaries() unexpected error: %v", err)
+	}
+	want := "Dictionary at '" + community + "' is valid (1 terms in use, 0 earlier spellings overridden).\n" +
+		"Dictionary at '" + company + "' is valid (2 terms in use, 1 earlier spellings overridden).\n" +
+		"  overrides 'Go' from '" + community + "'\n"
+	if out != want {
+		t.Errorf("validateDictionaries() output = %q, want %q", out, want)
+	}
+
+	// An overridden entry is dropped with its aliases and counted once per entry
+	k8s := filepath.Join(dir, "k8s.yaml")
+	k8sOverride := filepath.Join(dir, "k8s-override.yaml")
+	writeTestFile(t, k8s, "terms:\n  - term: Kubernetes\n    yomi: クバネティス\n    aliases: [K8s]\n    case_sensitive: false\n  - term: Helm\n    yomi: ヘルム\n    aliases: [helm]\n")
+	writeTestFile(t, k8sOverride, "terms:\n  - term: kubernetes\n    yomi: くーばね\n")
+	out = captureStdout(t, func() { err = validateDictionaries([]string{k8s, k8sOverride}) })
+	want = "Dictionary at '" + k8s + "' is valid (1 terms in use, 0 earlier spellings overridden).\n" +
+		"Dictionary at '" + k8sOverride + "' is valid (1 terms in use, 2 earlier spellings overridden).\n" +
+		"  overrides 'K8s' from '" + k8s + "'\n" +
+		"  overrides 'Kubernetes' from '" + k8s + "'\n"
+	if err != nil || out != want {
+		t.Errorf("validateDictionaries() = %q, %v, want %q", out, err, want)
+	}
+
+	out = captureStdout(t, func() { err = validateDictionaries([]string{community}) })
+	if err != nil || out != "Dictionary at '"+community+"' is valid.\n" {
+		t.Errorf("validateDictionaries() = %q, %v, want a single line for one dictionary", out, err)
+	}
+	// A directory is validated as a whole
+	tree := filepath.Join(dir, "dictionary")
+	writeTestFile(t, filepath.Join(tree, "a.yaml"), "terms:\n  - term: Angular\n    yomi: アンギュラー\n")
+	writeTestFile(t, filepath.Join(tree, "g", "go.yaml"), "terms:\n  - term: Go\n    yomi: ゴー\n")
+	out = captureStdout(t, func() { err = validateDictionaries([]string{tree}) })
+	if want := "Dictionary at '" + tree + "' is valid (2 files).\n"; err != nil || out != want {
+		t.Errorf("validateDictionaries() = %q, %v, want %q", out, err, want)
+	}
+	writeTestFile(t, filepath.Join(tree, "g", "golang.yaml"), "terms:\n  - term: Go\n    yomi: ゴーラング\n")
+	captureStdout(t, func() { err = validateDictionaries([]string{tree}) })
+	if err == nil || !strings.Contains(err.Error(), "golang.yaml:2") {
+		t.Errorf("validateDictionaries() error = %v, want the location of the duplicate", err)
+	}
+}
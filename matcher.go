@@ -0,0 +1,252 @@
+package main
+
+import "sort"
+
+// Match is a single dictionary hit found by a Matcher.
+type Match struct {
+	Start int    // Byte offset of the first byte of the match
+	End   int    // Byte offset just past the last byte of the match
+	Term  string // Dictionary key that matched
+}
+
+// Matcher finds every dictionary term in a text in a single pass using the
+// Aho-Corasick algorithm. It is built once from the loaded dictionary and is
+// safe for concurrent use, so the same automaton can be shared by all text
+// nodes and files. Scan time depends on the length of the text and the number
+// of matches, not on the size of the dictionary.
+//
+// Terms that are not case sensitive are kept in a second automaton that runs
+// over the text with ASCII letters folded to lower case.
+type Matcher struct {
+	exact      *automaton // Case-sensitive keys
+	folded     *automaton // Case-insensitive keys, folded with foldASCII
+	priorities map[string]int
+}
+
+// automaton is an Aho-Corasick automaton over a set of patterns.
+type automaton struct {
+	nodes []acNode
+	terms []string // Pattern index -> dictionary key
+}
+
+// acNode is a state of the Aho-Corasick automaton.
+type acNode struct {
+	next   map[byte]int
+	fail   int // State to fall back to when no transition exists
+	term   int // Index into automaton.terms ending at this state, or -1
+	output int // Nearest state on the fail chain that ends a term, or -1
+}
+
+// NewMatcher builds a Matcher from the keys of the given term dictionary.
+func NewMatcher(termMap map[string]Term) *Matcher {
+	keys := make([]string, 0, len(termMap))
+	for key := range termMap {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	// Sort the keys so that the automaton layout does not depend on map order.
+	sort.Strings(keys)
+
+	m := &Matcher{priorities: make(map[string]int)}
+	var exact, folded []string
+	for _, key := range keys {
+		term := termMap[key]
+		if term.Priority != 0 {
+			m.priorities[key] = term.Priority
+		}
+		if term.IsCaseSensitive() {
+			exact = append(exact, key)
+		} else {
+			folded = append(folded, key)
+		}
+	}
+	m.exact = newAutomaton(exact, func(key string) string { return key })
+	m.folded = newAutomaton(folded, foldASCII)
+	return m
+}
+
+// newAutomaton builds an automaton that reports the given dictionary keys, matching each
+// key as transformed by pattern.
+func newAutomaton(terms []string, pattern func(string) string) *automaton {
+	a := &automaton{terms: terms}
+	a.nodes = append(a.nodes, newACNode())
+
+	// Build the trie
+	for i, term := range terms {
+		p := pattern(term)
+		state := 0
+		for j := 0; j < len(p); j++ {
+			next, ok := a.nodes[state].next[p[j]]
+			if !ok {
+				next = len(a.nodes)
+				a.nodes = append(a.nodes, newACNode())
+				a.nodes[state].next[p[j]] = next
+			}
+			state = next
+		}
+		a.nodes[state].term = i
+	}
+
+	// Compute failure and output links in breadth-first order
+	queue := make([]int, 0, len(a.nodes))
+	for _, child := range a.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for b, child := range a.nodes[state].next {
+			fail := a.nodes[state].fail
+			for {
+				if next, ok := a.nodes[fail].next[b]; ok {
+					a.nodes[child].fail = next
+					break
+				}
+				if fail == 0 {
+					a.nodes[child].fail = 0
+					break
+				}
+				fail = a.nodes[fail].fail
+			}
+			failState := a.nodes[child].fail
+			if a.nodes[failState].term >= 0 {
+				a.nodes[child].output = failState
+			} else {
+				a.nodes[child].output = a.nodes[failState].output
+			}
+			queue = append(queue, child)
+		}
+	}
+
+	return a
+}
+
+func newACNode() acNode {
+	return acNode{next: make(map[byte]int), term: -1, output: -1}
+}
+
+// findAll appends every occurrence of every pattern in text to matches.
+func (a *automaton) findAll(text string, matches []Match) []Match {
+	if len(a.terms) == 0 {
+		return matches
+	}
+	state := 0
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		for {
+			if next, ok := a.nodes[state].next[b]; ok {
+				state = next
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = a.nodes[state].fail
+		}
+
+		for s := state; s >= 0; s = a.nodes[s].output {
+			if t := a.nodes[s].term; t >= 0 {
+				term := a.terms[t]
+				matches = append(matches, Match{Start: i + 1 - len(term), End: i + 1, Term: term})
+			}
+		}
+	}
+	return matches
+}
+
+// FindAll returns every occurrence of every dictionary term in text,
+// including overlapping ones. Matches are ordered by start offset, with longer
+// matches first when several start at the same offset.
+func (m *Matcher) FindAll(text string) []Match {
+	matches := m.exact.findAll(text, nil)
+	matches = m.folded.findAll(foldASCII(text), matches)
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		if matches[i].End != matches[j].End {
+			return matches[i].End > matches[j].End
+		}
+		return matches[i].Term < matches[j].Term
+	})
+	return matches
+}
+
+// foldASCII returns s with ASCII letters in lower case. Unlike strings.ToLower it
+// never changes the length of s, so offsets into the folded text apply to s.
+func foldASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if 'A' <= s[i] && s[i] <= 'Z' {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if 'A' <= b[j] && b[j] <= 'Z' {
+					b[j] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
+
+// FindNonOverlapping returns a deterministic set of non-overlapping matches in text,
+// ordered by start offset. Only matches for which accept returns true are
+// considered; a nil accept considers every match.
+//
+// Overlaps are resolved leftmost-longest: a match starting earlier wins, and among
+// matches starting at the same offset the longer one wins. A term's Priority
+// overrides this, so a higher-priority match always beats an overlapping
+// lower-priority one. Remaining ties are broken by the term itself.
+func (m *Matcher) FindNonOverlapping(text string, accept func(Match) bool) []Match {
+	var candidates []Match
+	for _, match := range m.FindAll(text) {
+		if accept == nil || accept(match) {
+			candidates = append(candidates, match)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if pa, pb := m.priorities[a.Term], m.priorities[b.Term]; pa != pb {
+			return pa > pb
+		}
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.End != b.End {
+			return a.End > b.End
+		}
+		return a.Term < b.Term
+	})
+
+	taken := make([]bool, len(text))
+	var selected []Match
+	for _, match := range candidates {
+		if overlapsTaken(taken, match) {
+			continue
+		}
+		for i := match.Start; i < match.End; i++ {
+			taken[i] = true
+		}
+		selected = append(selected, match)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Start < selected[j].Start
+	})
+	return selected
+}
+
+func overlapsTaken(taken []bool, match Match) bool {
+	for i := match.Start; i < match.End; i++ {
+		if taken[i] {
+			return true
+		}
+	}
+	return false
+}
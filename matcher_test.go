@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// --- Test Matcher ---
+
+func TestMatcherFindAll(t *testing.T) {
+	termMap := map[string]Term{
+		"he":   {Term: "he", Yomi: "ヒー"},
+		"she":  {Term: "she", Yomi: "シー"},
+		"his":  {Term: "his", Yomi: "ヒズ"},
+		"hers": {Term: "hers", Yomi: "ハーズ"},
+		"Vite": {Term: "Vite", Yomi: "ヴィート"},
+		"Go言語": {Term: "Go言語", Yomi: "ゴーげんご"},
+		"ヴィート": {Term: "ヴィート", Yomi: "ヴィート"},
+	}
+	matcher := NewMatcher(termMap)
+
+	tests := []struct {
+		name string
+		text string
+		want []Match
+	}{
+		{
+			name: "no matches",
+			text: "nothing to see",
+			want: nil,
+		},
+		{
+			name: "classic overlapping patterns",
+			text: "ushers",
+			want: []Match{
+				{Start: 1, End: 4, Term: "she"},
+				{Start: 2, End: 6, Term: "hers"},
+				{Start: 2, End: 4, Term: "he"},
+			},
+		},
+		{
+			name: "repeated term",
+			text: "Vite and Vite",
+			want: []Match{
+				{Start: 0, End: 4, Term: "Vite"},
+				{Start: 9, End: 13, Term: "Vite"},
+			},
+		},
+		{
+			name: "multibyte terms report byte offsets",
+			text: "Go言語とヴィート",
+			want: []Match{
+				{Start: 0, End: 8, Term: "Go言語"},
+				{Start: 11, End: 23, Term: "ヴィート"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matcher.FindAll(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAll(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherEmptyDictionary(t *testing.T) {
+	matcher := NewMatcher(map[string]Term{})
+	if got := matcher.FindAll("Vite"); got != nil {
+		t.Errorf("FindAll() with empty dictionary = %+v, want nil", got)
+	}
+}
+
+func TestMatcherFindAll_CaseInsensitive(t *testing.T) {
+	caseSensitive := false
+	termMap := map[string]Term{
+		"GitHub": {Term: "GitHub", Yomi: "ギットハブ", CaseSensitive: &caseSensitive},
+		"Go":     {Term: "Go", Yomi: "ゴー"},
+	}
+	got := NewMatcher(termMap).FindAll("github GITHUB go Go")
+	want := []Match{
+		{Start: 0, End: 6, Term: "GitHub"},
+		{Start: 7, End: 13, Term: "GitHub"},
+		{Start: 17, End: 19, Term: "Go"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAll() = %v, want %v", got, want)
+	}
+}
+
+func TestFoldASCII(t *testing.T) {
+	for input, want := range map[string]string{"": "", "go": "go", "GitHub": "github", "Go言語": "go言語", "ＧＯ": "ＧＯ"} {
+		if got := foldASCII(input); got != want {
+			t.Errorf("foldASCII(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestMatcherFindNonOverlapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		termMap map[string]Term
+		text    string
+		want    []Match
+	}{
+		{
+			name: "longest match wins at the same start",
+			termMap: map[string]Term{
+				"Go":         {Term: "Go", Yomi: "ゴー"},
+				"Go modules": {Term: "Go modules", Yomi: "ゴーモジュールズ"},
+			},
+			text: "Go modules and Go",
+			want: []Match{
+				{Start: 0, End: 10, Term: "Go modules"},
+				{Start: 15, End: 17, Term: "Go"},
+			},
+		},
+		{
+			name: "leftmost match wins over a later overlapping one",
+			termMap: map[string]Term{
+				"Visual Studio": {Term: "Visual Studio", Yomi: "ビジュアルスタジオ"},
+				"Studio Code":   {Term: "Studio Code", Yomi: "スタジオコード"},
+			},
+			text: "Visual Studio Code",
+			want: []Match{
+				{Start: 0, End: 13, Term: "Visual Studio"},
+			},
+		},
+		{
+			name: "priority overrides leftmost-longest",
+			termMap: map[string]Term{
+				"Visual Studio": {Term: "Visual Studio", Yomi: "ビジュアルスタジオ"},
+				"Studio Code":   {Term: "Studio Code", Yomi: "スタジオコード", Priority: 1},
+			},
+			text: "Visual Studio Code",
+			want: []Match{
+				{Start: 7, End: 18, Term: "Studio Code"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMatcher(tt.termMap).FindNonOverlapping(tt.text, nil)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindNonOverlapping(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherFindNonOverlapping_Accept(t *testing.T) {
+	matcher := NewMatcher(map[string]Term{
+		"Go":         {Term: "Go", Yomi: "ゴー"},
+		"Go modules": {Term: "Go modules", Yomi: "ゴーモジュールズ"},
+	})
+	// Rejecting the longer match lets the shorter one through.
+	got := matcher.FindNonOverlapping("Go modules", func(m Match) bool { return m.Term != "Go modules" })
+	want := []Match{{Start: 0, End: 2, Term: "Go"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindNonOverlapping() = %+v, want %+v", got, want)
+	}
+}
+
+// --- Benchmarks ---
+
+// benchmarkTermMap returns a synthetic dictionary with n terms, always including "Vite" and "gRPC".
+func benchmarkTermMap(n int) map[string]Term {
+	termMap := map[string]Term{
+		"Vite": {Term: "Vite", Yomi: "ヴィート"},
+		"gRPC": {Term: "gRPC", Yomi: "ジーアールピーシー"},
+	}
+	for i := 0; len(termMap) < n; i++ {
+		term := fmt.Sprintf("Term%05d", i)
+		termMap[term] = Term{Term: term, Yomi: "ターム"}
+	}
+	return termMap
+}
+
+// benchmarkDocument returns a Markdown document with the given number of lines.
+func benchmarkDocument(lines int) []byte {
+	var b strings.Builder
+	for i := 0; i < lines; i++ {
+		if i%10 == 0 {
+			b.WriteString("\n## Section\n\n")
+		}
+		b.WriteString("Vite is a build tool and gRPC is a framework, both used in this post.\n")
+	}
+	return []byte(b.String())
+}
+
+func BenchmarkProcessMarkdown_Scan(b *testing.B) {
+	content := benchmarkDocument(1000)
+	for _, size := range []int{10, 100, 1000, 10000} {
+		b.Run(fmt.Sprintf("terms=%d", size), func(b *testing.B) {
+			processor := NewProcessor(benchmarkTermMap(size))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := processor.Process(content, Options{Scan: true}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewMatcher(b *testing.B) {
+	termMap := benchmarkTermMap(1000)
+	for i := 0; i < b.N; i++ {
+		NewMatcher(termMap)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reading is one of the readings of a term listed under "readings" in the dictionary.
+type Reading struct {
+	Yomi      string `yaml:"yomi"`
+	Preferred bool   `yaml:"preferred,omitempty"` // Exactly one reading of a term is preferred
+	Note      string `yaml:"note,omitempty"`      // Why the reading is used or disputed
+}
+
+// Ways to show the alternate (not preferred) readings of a term, selected with --alternates.
+const (
+	AlternatesNone     = "none"     // Do not show alternate readings
+	AlternatesTitle    = "title"    // title attribute on the ruby element (html renderers only)
+	AlternatesGlossary = "glossary" // Extra column in the glossary generated by --glossary
+)
+
+// resolveReadings checks the readings of term and sets its Yomi to the preferred one.
+// A term without readings is left as it is.
+func resolveReadings(term *Term) error {
+	if len(term.Readings) == 0 {
+		return nil
+	}
+	var preferred []string
+	for _, r := range term.Readings {
+		if r.Yomi == "" {
+			return fmt.Errorf("empty reading for term %s", term.Term)
+		}
+		if r.Preferred {
+			preferred = append(preferred, r.Yomi)
+		}
+	}
+	if len(preferred) != 1 {
+		return fmt.Errorf("term %s needs exactly one preferred reading, found %d", term.Term, len(preferred))
+	}
+	if term.Yomi != "" && term.Yomi != preferred[0] {
+		return fmt.Errorf("yomi of term %s differs from its preferred reading %s", term.Term, preferred[0])
+	}
+	term.Yomi = preferred[0]
+	return nil
+}
+
+// Alternates returns the readings of the term other than the preferred one.
+func (t Term) Alternates() []Reading {
+	var alternates []Reading
+	for _, r := range t.Readings {
+		if !r.Preferred {
+			alternates = append(alternates, r)
+		}
+	}
+	return alternates
+}
+
+// formatReadings lists readings for display, each followed by its note in parentheses.
+func formatReadings(readings []Reading) string {
+	list := make([]string, len(readings))
+	for i, r := range readings {
+		list[i] = r.Yomi
+		if r.Note != "" {
+			list[i] += "（" + r.Note + "）"
+		}
+	}
+	return strings.Join(list, "、")
+}
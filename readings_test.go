@@ -0,0 +1,180 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadDictionary_Readings(t *testing.T) {
+	tests := []struct {
+		name        string
+		yamlContent string
+		wantYomi    string
+		errContains string
+	}{
+		{
+			name:        "preferred reading becomes the yomi",
+			yamlContent: "terms:\n  - term: Vite\n    readings:\n      - yomi: ヴィート\n        preferred: true\n      - yomi: バイト\n        note: 英語読み\n",
+			wantYomi:    "ヴィート",
+		},
+		{
+			name:        "yomi may repeat the preferred reading",
+			yamlContent: "terms:\n  - term: Vite\n    yomi: ヴィート\n    readings:\n      - yomi: ヴィート\n        preferred: true\n      - yomi: バイト\n",
+			wantYomi:    "ヴィート",
+		},
+		{
+			name:        "no preferred reading",
+			yamlContent: "terms:\n  - term: Go\n    yomi: ゴー\n  - term: nginx\n    readings:\n      - yomi: エンジンエックス\n      - yomi: エンジンクス\n",
+			errContains: "invalid entry found: term nginx needs exactly one preferred reading, found 0",
+		},
+		{
+			name:        "two preferred readings",
+			yamlContent: "terms:\n  - term: nginx\n    readings:\n      - yomi: エンジンエックス\n        preferred: true\n      - yomi: エンジンクス\n        preferred: true\n",
+			errContains: "needs exactly one preferred reading, found 2",
+		},
+		{
+			name:        "empty reading",
+			yamlContent: "terms:\n  - term: Azure\n    readings:\n      - yomi: アジュール\n        preferred: true\n      - note: 読み方なし\n",
+			errContains: "empty reading for term Azure",
+		},
+		{
+			name:        "yomi differs from the preferred reading",
+			yamlContent: "terms:\n  - term: Linux\n    yomi: ライナックス\n    readings:\n      - yomi: リナックス\n        preferred: true\n",
+			errContains: "yomi of term Linux differs from its preferred reading リナックス",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := createTempDictFile(t, tt.yamlContent)
+			defer os.Remove(filePath)
+
+			got, err := LoadDictionary(filePath)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("LoadDictionary() error = %v, want error containing %q", err, tt.errContains)
+				}
+				if !strings.Contains(err.Error(), filePath+":") {
+					t.Errorf("LoadDictionary() error = %v, want the location of the entry", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadDictionary() unexpected error: %v", err)
+			}
+			if got["Vite"].Yomi != tt.wantYomi {
+				t.Errorf("Yomi = %q, want %q", got["Vite"].Yomi, tt.wantYomi)
+			}
+		})
+	}
+}
+
+func TestTermAlternates(t *testing.T) {
+	term := Term{Term: "nginx", Yomi: "エンジンエックス", Readings: []Reading{
+		{Yomi: "エンジンエックス", Preferred: true},
+		{Yomi: "エンジンクス", Note: "よくある誤読"},
+		{Yomi: "エヌジンクス"},
+	}}
+	want := []Reading{{Yomi: "エンジンクス", Note: "よくある誤読"}, {Yomi: "エヌジンクス"}}
+	if got := term.Alternates(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Alternates() = %v, want %v", got, want)
+	}
+	if got, want := formatReadings(want), "エンジンクス（よくある誤読）、エヌジンクス"; got != want {
+		t.Errorf("formatReadings() = %q, want %q", got, want)
+	}
+	if got := (Term{Term: "Go", Yomi: "ゴー"}).Alternates(); got != nil {
+		t.Errorf("Alternates() of a term without readings = %v, want nil", got)
+	}
+}
+
+func TestProcess_Alternates(t *testing.T) {
+	vite := Term{Term: "Vite", Yomi: "ヴィート", Ref: "https://ja.vitejs.dev/", Readings: []Reading{
+		{Yomi: "ヴィート", Preferred: true},
+		{Yomi: "バイト", Note: "英語読み"},
+	}}
+	processor := NewProcessor(map[string]Term{
+		"Vite": vite,
+		"Go":   {Term: "Go", Yomi: "ゴー"},
+	})
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "title attribute",
+			opts:       Options{Alternates: AlternatesTitle},
+			input:      "Vite:rubi and Go:rubi",
+			wantOutput: `<ruby title="別の読み方: バイト（英語読み）">Vite<rt>ヴィート</rt></ruby> and <ruby>Go<rt>ゴー</rt></ruby>`,
+		},
+		{
+			name:       "title attribute with the reading source",
+			opts:       Options{Scan: true, Ref: RefTitle, Alternates: AlternatesTitle, Renderer: "html-rp"},
+			input:      "Vite",
+			wantOutput: `<ruby title="https://ja.vitejs.dev/ / 別の読み方: バイト（英語読み）">Vite<rp>(</rp><rt>ヴィート</rt><rp>)</rp></ruby>`,
+		},
+		{
+			name:       "inline readings have no alternates",
+			opts:       Options{Alternates: AlternatesTitle},
+			input:      "Vite:rubi(ビート)",
+			wantOutput: `<ruby>Vite<rt>ビート</rt></ruby>`,
+		},
+		{
+			name:       "glossary style does not change the markup",
+			opts:       Options{Renderer: "aozora", Alternates: AlternatesGlossary},
+			input:      "Vite:rubi",
+			wantOutput: "｜Vite《ヴィート》",
+		},
+		{
+			name:  "glossary column",
+			opts:  Options{Scan: true, Glossary: true, Alternates: AlternatesGlossary},
+			input: "Vite and Go\n",
+			wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby> and <ruby>Go<rt>ゴー</rt></ruby>\n\n" +
+				"<!-- rubi:glossary:start -->\n## 用語と読み方\n\n" +
+				"| 用語 | 読み方 | 別の読み方 | 出典 |\n| :--- | :--- | :--- | :--- |\n" +
+				"| Vite | ヴィート | バイト（英語読み） | <https://ja.vitejs.dev/> |\n" +
+				"| Go | ゴー |  |  |\n" +
+				"\n<!-- rubi:glossary:end -->\n",
+		},
+		{
+			name:       "refresh replaces an alternate reading with the preferred one",
+			opts:       Options{Refresh: true, Alternates: AlternatesTitle},
+			input:      "<ruby>Vite<rt>バイト</rt></ruby>",
+			wantOutput: `<ruby title="別の読み方: バイト（英語読み）">Vite<rt>ヴィート</rt></ruby>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if got := string(result.Content); got != tt.wantOutput {
+				t.Fatalf("Process() = %q, want %q", got, tt.wantOutput)
+			}
+
+			again, err := processor.Process(result.Content, tt.opts)
+			if err != nil {
+				t.Fatalf("Process() second run unexpected error: %v", err)
+			}
+			if len(again.Patches) > 0 {
+				t.Errorf("Process() is not idempotent: second run = %q", again.Content)
+			}
+		})
+	}
+}
+
+func TestNewRenderer_AlternatesErrors(t *testing.T) {
+	if _, err := newRenderer("plain", RefNone, AlternatesTitle); err == nil || !strings.Contains(err.Error(), "--alternates title requires the html or html-rp renderer") {
+		t.Errorf("newRenderer() error = %v, want html renderer error", err)
+	}
+	if _, err := newRenderer("html", RefNone, "tooltip"); err == nil || !strings.Contains(err.Error(), "unknown alternates style 'tooltip'") {
+		t.Errorf("newRenderer() error = %v, want unknown alternates style error", err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Renderer produces the markup that annotates a term with its reading.
+// Process creates a new Renderer for every document, so an implementation may
+// keep per-document state such as footnote numbers.
+type Renderer interface {
+	// Begin is called with the document before any term is rendered.
+	Begin(content []byte)
+	// Render returns the text that replaces word, which is given as written in the document.
+	// ref is the reading source from the dictionary, or "" if there is none, and
+	// alternates are the other readings of the term, which may be ignored.
+	Render(word, reading, ref string, alternates []Reading) string
+	// Finish returns text to append to the document after every term has been rendered, or "" if there is none.
+	Finish() string
+	// Annotated returns the end of the markup this renderer put around the word at content[start:end],
+	// or -1 if the word is not annotated yet, so that converting a document again leaves it as is.
+	// reading is the reading of the term in the dictionary.
+	Annotated(content []byte, start, end int, reading string) int
+}
+
+// defaultRenderer is the renderer used when none is selected.
+const defaultRenderer = "html"
+
+// Ways to show the reading source (Term.Ref) in the output, selected with --ref.
+const (
+	RefNone     = "none"     // Do not show the source
+	RefTitle    = "title"    // title attribute on the ruby element (html renderers only)
+	RefLink     = "link"     // Link around the reading (html renderers only)
+	RefFootnote = "footnote" // "読み方の出典" section at the end of the document
+)
+
+// renderers maps the names accepted by --renderer to their constructors.
+var renderers = map[string]func() Renderer{
+	"html":     func() Renderer { return htmlRenderer{} },
+	"html-rp":  func() Renderer { return htmlRenderer{parentheses: true} },
+	"aozora":   func() Renderer { return formatRenderer("｜%s《%s》") },
+	"denden":   func() Renderer { return formatRenderer("{%s|%s}") },
+	"plain":    func() Renderer { return formatRenderer("%s（%s）") },
+	"footnote": func() Renderer { return &footnoteRenderer{labels: make(map[string]int)} },
+}
+
+// newRenderer returns a new Renderer by name that shows the reading source as
+// selected by ref and the alternate readings as selected by alternates.
+// An empty name selects defaultRenderer.
+func newRenderer(name, ref, alternates string) (Renderer, error) {
+	if name == "" {
+		name = defaultRenderer
+	}
+	newFunc, ok := renderers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown renderer '%s' (expected %s)", name, strings.Join(rendererNames(), ", "))
+	}
+	renderer := newFunc()
+
+	switch ref {
+	case "", RefNone, RefFootnote:
+		// The footnote section is generated by Process for every renderer
+	case RefTitle, RefLink:
+		h, ok := renderer.(htmlRenderer)
+		if !ok {
+			return nil, fmt.Errorf("--ref %s requires the html or html-rp renderer, not '%s'", ref, name)
+		}
+		h.ref = ref
+		renderer = h
+	default:
+		return nil, fmt.Errorf("unknown ref style '%s' (expected none, title, link or footnote)", ref)
+	}
+
+	switch alternates {
+	case "", AlternatesNone, AlternatesGlossary:
+		// The glossary column is generated by Process for every renderer
+	case AlternatesTitle:
+		h, ok := renderer.(htmlRenderer)
+		if !ok {
+			return nil, fmt.Errorf("--alternates title requires the html or html-rp renderer, not '%s'", name)
+		}
+		h.alternates = true
+		renderer = h
+	default:
+		return nil, fmt.Errorf("unknown alternates style '%s' (expected none, title or glossary)", alternates)
+	}
+	return renderer, nil
+}
+
+// rendererNames returns the names of all renderers in alphabetical order.
+func rendererNames() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// htmlRenderer renders HTML ruby tags, optionally with <rp> parentheses for
+// browsers that do not support ruby. With ref set to RefTitle or RefLink, the
+// reading source is shown as a tooltip or a link on the reading. With alternates,
+// the other readings of the term are added to the tooltip.
+type htmlRenderer struct {
+	parentheses bool
+	ref         string
+	alternates  bool
+}
+
+func (r htmlRenderer) Render(word, reading, ref string, alternates []Reading) string {
+	var titles []string
+	rt := html.EscapeString(reading)
+	if ref != "" {
+		switch r.ref {
+		case RefTitle:
+			titles = append(titles, ref)
+		case RefLink:
+			rt = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(ref), rt)
+		}
+	}
+	if r.alternates && len(alternates) > 0 {
+		titles = append(titles, "別の読み方: "+formatReadings(alternates))
+	}
+	open := "<ruby>"
+	if len(titles) > 0 {
+		open = fmt.Sprintf(`<ruby title="%s">`, html.EscapeString(strings.Join(titles, " / ")))
+	}
+	if r.parentheses {
+		return fmt.Sprintf("%s%s<rp>(</rp><rt>%s</rt><rp>)</rp></ruby>", open, html.EscapeString(word), rt)
+	}
+	return fmt.Sprintf("%s%s<rt>%s</rt></ruby>", open, html.EscapeString(word), rt)
+}
+
+func (htmlRenderer) Begin(content []byte) {}
+
+func (htmlRenderer) Finish() string { return "" }
+
+// Annotated always returns -1, as existing HTML ruby is found in the parsed document instead (see findRubySpans).
+func (htmlRenderer) Annotated(content []byte, start, end int, reading string) int { return -1 }
+
+// formatRenderer renders plain-text markup from a format taking the word and the reading,
+// e.g. "｜%s《%s》" for Aozora Bunko style ruby.
+type formatRenderer string
+
+func (r formatRenderer) Render(word, reading, ref string, alternates []Reading) string {
+	return fmt.Sprintf(string(r), word, reading)
+}
+
+func (formatRenderer) Begin(content []byte) {}
+
+func (formatRenderer) Finish() string { return "" }
+
+// Annotated matches the format around the word, with a non-empty reading on the same line.
+// A format with nothing before the word, such as "%s（%s）", cannot be told apart from an
+// ordinary parenthetical like "Vite（ビルドツール）", so it needs the reading of the dictionary.
+func (r formatRenderer) Annotated(content []byte, start, end int, reading string) int {
+	parts := strings.SplitN(string(r), "%s", 3) // Before the word, between the word and the reading, after the reading
+	rest := content[end:]
+	if !bytes.HasSuffix(content[:start], []byte(parts[0])) || !bytes.HasPrefix(rest, []byte(parts[1])) {
+		return -1
+	}
+	line, _, _ := bytes.Cut(rest[len(parts[1]):], []byte("\n"))
+	i := bytes.Index(line, []byte(parts[2]))
+	if i <= 0 || (parts[0] == "" && string(line[:i]) != reading) {
+		return -1
+	}
+	return end + len(parts[1]) + i + len(parts[2])
+}
+
+// footnoteRenderer renders a Markdown footnote reference after the word and
+// appends the footnote definitions, one per distinct term and reading, to the document.
+type footnoteRenderer struct {
+	labels      map[string]int // Footnote number by word and reading
+	count       int            // Highest footnote number in the document
+	definitions []string       // Definitions to append to the document
+}
+
+// footnoteReference and footnoteDefinition match the markup of footnoteRenderer.
+var (
+	footnoteReference  = regexp.MustCompile(`^\[\^rubi-\d+\]`)
+	footnoteDefinition = regexp.MustCompile(`(?m)^\[\^rubi-(\d+)\]: (.*)（(.*)）\r?$`)
+)
+
+// Begin takes over the footnotes of an earlier run found in content, so that
+// new footnotes are numbered after them and existing definitions are reused.
+func (r *footnoteRenderer) Begin(content []byte) {
+	for _, m := range footnoteDefinition.FindAllSubmatch(content, -1) {
+		n, err := strconv.Atoi(string(m[1]))
+		if err != nil {
+			continue
+		}
+		r.labels[string(m[2])+"\x00"+string(m[3])] = n
+		r.count = max(r.count, n)
+	}
+}
+
+func (r *footnoteRenderer) Render(word, reading, ref string, alternates []Reading) string {
+	key := word + "\x00" + reading
+	n, ok := r.labels[key]
+	if !ok {
+		r.count++
+		n = r.count
+		r.labels[key] = n
+		r.definitions = append(r.definitions, fmt.Sprintf("[^rubi-%d]: %s（%s）\n", n, word, reading))
+	}
+	return fmt.Sprintf("%s[^rubi-%d]", word, n)
+}
+
+func (r *footnoteRenderer) Finish() string {
+	return strings.Join(r.definitions, "")
+}
+
+// Annotated matches a footnote reference right after the word, or a footnote definition
+// anywhere on the line of the word, as the definitions are ordinary text to the parser.
+func (r *footnoteRenderer) Annotated(content []byte, start, end int, reading string) int {
+	if m := footnoteReference.Find(content[end:]); m != nil {
+		return end + len(m)
+	}
+	lineStart := bytes.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := len(content)
+	if i := bytes.IndexByte(content[start:], '\n'); i >= 0 {
+		lineEnd = start + i
+	}
+	if footnoteDefinition.Match(content[lineStart:lineEnd]) {
+		return lineEnd
+	}
+	return -1
+}
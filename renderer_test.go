@@ -0,0 +1,226 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// --- Test renderers ---
+
+func TestProcess_Renderers(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+
+	tests := []struct {
+		renderer   string
+		input      string
+		wantOutput string
+	}{
+		{renderer: "", input: "Vite:rubi", wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby>"},
+		{renderer: "html", input: "Vite:rubi", wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby>"},
+		{renderer: "html-rp", input: "Vite:rubi", wantOutput: "<ruby>Vite<rp>(</rp><rt>ヴィート</rt><rp>)</rp></ruby>"},
+		{renderer: "aozora", input: "Vite:rubi", wantOutput: "｜Vite《ヴィート》"},
+		{renderer: "denden", input: "Vite:rubi", wantOutput: "{Vite|ヴィート}"},
+		{renderer: "plain", input: "Vite:rubi", wantOutput: "Vite（ヴィート）"},
+		{
+			renderer:   "footnote",
+			input:      "Vite:rubi and Go:rubi, Vite:rubi again\n",
+			wantOutput: "Vite[^rubi-1] and Go[^rubi-2], Vite[^rubi-1] again\n\n[^rubi-1]: Vite（ヴィート）\n[^rubi-2]: Go（ゴー）\n",
+		},
+		{
+			renderer:   "footnote",
+			input:      "No newline Vite:rubi",
+			wantOutput: "No newline Vite[^rubi-1]\n\n[^rubi-1]: Vite（ヴィート）\n",
+		},
+		{renderer: "footnote", input: "Nothing to convert\n", wantOutput: "Nothing to convert\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.renderer, func(t *testing.T) {
+			result, err := processor.Process([]byte(tt.input), Options{Renderer: tt.renderer, Quiet: true})
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestProcess_UnknownRenderer(t *testing.T) {
+	_, err := NewProcessor(createTestTermMap()).Process([]byte("Vite:rubi"), Options{Renderer: "latex"})
+	if err == nil || !strings.Contains(err.Error(), "unknown renderer 'latex'") {
+		t.Errorf("Process() error = %v, want unknown renderer error", err)
+	}
+}
+
+func TestProcess_RenderersAreIdempotent(t *testing.T) {
+	termMap := createTestTermMap()
+	termMap["Go modules"] = Term{Term: "Go modules", Yomi: "ゴーモジュールズ"}
+	processor := NewProcessor(termMap)
+
+	for _, name := range rendererNames() {
+		for _, opts := range []Options{{Scan: true, Renderer: name}, {Scan: true, FirstOnly: true, Renderer: name}} {
+			first, err := processor.Process([]byte("Vite with Go modules and Go\n\nVite and gRPC again\n"), opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			second, err := processor.Process(first.Content, opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(second.Content) != string(first.Content) {
+				t.Errorf("Process(%+v) is not idempotent: first = %q, second = %q", opts, first.Content, second.Content)
+			}
+		}
+	}
+}
+
+func TestProcess_RenderersKeepEarlierMarkup(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "aozora",
+			opts:       Options{Scan: true, Renderer: "aozora"},
+			input:      "｜Vite《ヴィート》 and Go",
+			wantOutput: "｜Vite《ヴィート》 and ｜Go《ゴー》",
+		},
+		{
+			name:       "plain counts as the first occurrence",
+			opts:       Options{Scan: true, FirstOnly: true, Renderer: "plain"},
+			input:      "Vite（ヴィート） and Vite",
+			wantOutput: "Vite（ヴィート） and Vite",
+		},
+		{
+			name:       "plain needs the reading of the dictionary",
+			opts:       Options{Scan: true, Renderer: "plain"},
+			input:      "Vite（ビルドツール）は速い。Go（ゴー）",
+			wantOutput: "Vite（ヴィート）（ビルドツール）は速い。Go（ゴー）",
+		},
+		{
+			name:       "aozora keeps an outdated reading",
+			opts:       Options{Scan: true, Renderer: "aozora"},
+			input:      "｜Vite《ヴァイト》",
+			wantOutput: "｜Vite《ヴァイト》",
+		},
+		{
+			name:       "plain needs a reading",
+			opts:       Options{Scan: true, Renderer: "plain"},
+			input:      "Vite（） and Go",
+			wantOutput: "Vite（ヴィート）（） and Go（ゴー）",
+		},
+		{
+			name:       "footnotes are numbered after existing ones",
+			opts:       Options{Scan: true, Renderer: "footnote"},
+			input:      "Vite[^rubi-1] and Go, Vite\n\n[^rubi-1]: Vite（ヴィート）\n",
+			wantOutput: "Vite[^rubi-1] and Go[^rubi-2], Vite[^rubi-1]\n\n[^rubi-1]: Vite（ヴィート）\n\n[^rubi-2]: Go（ゴー）\n",
+		},
+		{
+			name:       "manual footnotes are numbered after existing ones",
+			opts:       Options{Renderer: "footnote"},
+			input:      "Vite[^rubi-1] and Go:rubi\n\n[^rubi-1]: Vite（ヴィート）\n",
+			wantOutput: "Vite[^rubi-1] and Go[^rubi-2]\n\n[^rubi-1]: Vite（ヴィート）\n\n[^rubi-2]: Go（ゴー）\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestProcess_Ref(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "title attribute",
+			opts:       Options{Ref: RefTitle},
+			input:      "Vite:rubi and Go:rubi",
+			wantOutput: `<ruby title="https://ja.vitejs.dev/">Vite<rt>ヴィート</rt></ruby> and <ruby>Go<rt>ゴー</rt></ruby>`,
+		},
+		{
+			name:       "link around the reading",
+			opts:       Options{Ref: RefLink, Renderer: "html-rp"},
+			input:      "Vite:rubi",
+			wantOutput: `<ruby>Vite<rp>(</rp><rt><a href="https://ja.vitejs.dev/">ヴィート</a></rt><rp>)</rp></ruby>`,
+		},
+		{
+			name:  "footnote section",
+			opts:  Options{Scan: true, Ref: RefFootnote},
+			input: "gRPC and Vite and Go\n",
+			wantOutput: "<ruby>gRPC<rt>ジーアールピーシー</rt></ruby> and <ruby>Vite<rt>ヴィート</rt></ruby> and <ruby>Go<rt>ゴー</rt></ruby>\n\n" +
+				"<!-- rubi:refs:start -->\n## 読み方の出典\n\n" +
+				"- gRPC（ジーアールピーシー）: <https://grpc.io/>\n" +
+				"- Vite（ヴィート）: <https://ja.vitejs.dev/>\n" +
+				"\n<!-- rubi:refs:end -->\n",
+		},
+		{
+			name:       "footnote section is not added without sources",
+			opts:       Options{Scan: true, Ref: RefFootnote},
+			input:      "Go\n",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby>\n",
+		},
+		{
+			name: "footnote section is updated in place",
+			opts: Options{Scan: true, Ref: RefFootnote},
+			input: "<ruby>Vite<rt>ヴィート</rt></ruby> and gRPC\n\n" +
+				"<!-- rubi:refs:start -->\n## 読み方の出典\n\n- Vite（ヴィート）: <https://ja.vitejs.dev/>\n\n<!-- rubi:refs:end -->\n\nThe end.\n",
+			wantOutput: "<ruby>Vite<rt>ヴィート</rt></ruby> and <ruby>gRPC<rt>ジーアールピーシー</rt></ruby>\n\n" +
+				"<!-- rubi:refs:start -->\n## 読み方の出典\n\n" +
+				"- Vite（ヴィート）: <https://ja.vitejs.dev/>\n" +
+				"- gRPC（ジーアールピーシー）: <https://grpc.io/>\n" +
+				"\n<!-- rubi:refs:end -->\n\nThe end.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+
+			// Running again must not change anything
+			again, err := processor.Process(result.Content, tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if len(again.Patches) > 0 {
+				t.Errorf("Process() is not idempotent: second run = %q", again.Content)
+			}
+		})
+	}
+}
+
+func TestNewRenderer_RefErrors(t *testing.T) {
+	if _, err := newRenderer("aozora", RefTitle, ""); err == nil || !strings.Contains(err.Error(), "requires the html or html-rp renderer") {
+		t.Errorf("newRenderer() error = %v, want html renderer error", err)
+	}
+	if _, err := newRenderer("html", "tooltip", ""); err == nil || !strings.Contains(err.Error(), "unknown ref style 'tooltip'") {
+		t.Errorf("newRenderer() error = %v, want unknown ref style error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Output formats accepted by --format.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// jsonReport is the top-level document written by --format json.
+type jsonReport struct {
+	Files []jsonFile `json:"files"`
+}
+
+// jsonFile describes the conversions in a single file.
+type jsonFile struct {
+	Path        string           `json:"path"`
+	Changed     bool             `json:"changed"`
+	Error       string           `json:"error,omitempty"`
+	Conversions []jsonConversion `json:"conversions"`
+}
+
+// jsonConversion describes a single term occurrence and the patch generated for it.
+type jsonConversion struct {
+	Term        string `json:"term"`
+	Reading     string `json:"reading,omitempty"`
+	Ref         string `json:"ref,omitempty"`
+	Dictionary  string `json:"dictionary,omitempty"` // Dictionary file the term was found in
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Mode        string `json:"mode"`
+	Replacement string `json:"replacement"`
+	Skipped     bool   `json:"skipped"`
+	SkipReason  string `json:"skip_reason,omitempty"`
+}
+
+// writeJSONReport writes the results of processFiles as an indented JSON report.
+func writeJSONReport(w io.Writer, files []string, results []fileResult) error {
+	report := jsonReport{Files: make([]jsonFile, 0, len(files))}
+	for i, result := range results {
+		file := jsonFile{Path: displayName(files[i]), Changed: result.Changed, Conversions: []jsonConversion{}}
+		if result.Err != nil {
+			file.Error = result.Err.Error()
+			report.Files = append(report.Files, file)
+			continue
+		}
+
+		replacements := make(map[int]string, len(result.Result.Patches))
+		for _, p := range result.Result.Patches {
+			replacements[p.Start] = string(p.NewText)
+		}
+		for _, o := range result.Result.Occurrences {
+			line, column := lineColumn(result.Original, o.Start)
+			conversion := jsonConversion{
+				Term:       o.Term,
+				Reading:    o.Yomi,
+				Ref:        o.Ref,
+				Dictionary: o.Source,
+				Start:      o.Start,
+				End:        o.End,
+				Line:       line,
+				Column:     column,
+				Mode:       o.Mode,
+				Skipped:    o.Skip != "",
+				SkipReason: o.Skip,
+			}
+			if replacement, ok := replacements[o.Start]; ok {
+				conversion.Replacement = replacement
+			} else {
+				conversion.Replacement = string(result.Original[o.Start:o.End])
+			}
+			file.Conversions = append(file.Conversions, conversion)
+		}
+		report.Files = append(report.Files, file)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// --- Test --format json ---
+
+func TestHandleMainCommand_JSONReport(t *testing.T) {
+	dir := t.TempDir()
+	dictFile := filepath.Join(dir, "dict.yaml")
+	writeTestFile(t, dictFile, "terms:\n  - term: Vite\n    yomi: ヴィート\n    ref: https://ja.vitejs.dev/\n")
+	doc := filepath.Join(dir, "doc.md")
+	writeTestFile(t, doc, "# Title\n\nVite と Vite\n")
+	manual := filepath.Join(dir, "manual.md")
+	writeTestFile(t, manual, "Nuxt:rubi\n")
+
+	var err error
+	out := captureStdout(t, func() {
+		err = handleMainCommand(&Config{DictPaths: []string{dictFile}, Scan: true, FirstOnly: true, Format: formatJSON, Jobs: 2, Inputs: []string{doc}})
+	})
+	if err != nil {
+		t.Fatalf("handleMainCommand() unexpected error: %v", err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("failed to parse JSON report %q: %v", out, err)
+	}
+	want := jsonReport{Files: []jsonFile{{
+		Path:    doc,
+		Changed: true,
+		Conversions: []jsonConversion{
+			{Term: "Vite", Reading: "ヴィート", Ref: "https://ja.vitejs.dev/", Dictionary: dictFile, Start: 9, End: 13, Line: 3, Column: 1, Mode: ModeScan, Replacement: "<ruby>Vite<rt>ヴィート</rt></ruby>"},
+			{Term: "Vite", Reading: "ヴィート", Ref: "https://ja.vitejs.dev/", Dictionary: dictFile, Start: 18, End: 22, Line: 3, Column: 8, Mode: ModeScan, Replacement: "Vite", Skipped: true, SkipReason: SkipFirstOnly},
+		},
+	}}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("JSON report = %+v, want %+v", report, want)
+	}
+
+	out = captureStdout(t, func() {
+		err = handleMainCommand(&Config{DictPaths: []string{dictFile}, Format: formatJSON, Jobs: 1, Inputs: []string{manual}})
+	})
+	if err != nil {
+		t.Fatalf("handleMainCommand() unexpected error: %v", err)
+	}
+	report = jsonReport{}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("failed to parse JSON report %q: %v", out, err)
+	}
+	want = jsonReport{Files: []jsonFile{{
+		Path:    manual,
+		Changed: true,
+		Conversions: []jsonConversion{
+			{Term: "Nuxt", Start: 0, End: 9, Line: 1, Column: 1, Mode: ModeManual, Replacement: "Nuxt", Skipped: true, SkipReason: SkipUnknownTerm},
+		},
+	}}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("JSON report = %+v, want %+v", report, want)
+	}
+
+	// The report never touches the files unless -w is given
+	if content, _ := os.ReadFile(doc); string(content) != "# Title\n\nVite と Vite\n" {
+		t.Errorf("doc.md content = %q, want it unchanged", content)
+	}
+}
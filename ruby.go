@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// rubyPattern matches the ruby markup generated by rubi's html and html-rp renderers,
+// including the title attribute or reading link added by --ref, at the start of its
+// input. The base text and the reading are captured as written in the source (HTML-escaped).
+var rubyPattern = regexp.MustCompile(`^<ruby(?: title="[^"\n]*")?>([^<\n]*)(?:<rp>[^<\n]*</rp>)?<rt>(?:<a href="[^"\n]*">)?([^<\n]*)(?:</a>)?</rt>(?:<rp>[^<\n]*</rp>)?</ruby>`)
+
+// rubyOpenTag and rubyCloseTag match the tags of any ruby element, whoever wrote it.
+var (
+	rubyOpenTag  = regexp.MustCompile(`(?i)^<ruby(?:\s[^>]*)?>`)
+	rubyCloseTag = regexp.MustCompile(`(?i)^</ruby\s*>`)
+)
+
+// rubySpan is ruby markup generated by rubi found in a document.
+type rubySpan struct {
+	Start   int    // Byte offset of "<ruby>"
+	End     int    // Byte offset just past "</ruby>"
+	Word    string // Base text as written in the source
+	Reading string // Reading as written in the source
+}
+
+// findRubySpans returns the ruby markup generated by rubi in the parsed document, in document order.
+// Only inline raw HTML is considered, so markup shown inside code spans, code blocks or HTML blocks is left alone.
+func findRubySpans(document ast.Node, content []byte) []rubySpan {
+	var spans []rubySpan
+	ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindCodeBlock, ast.KindFencedCodeBlock, ast.KindHTMLBlock, ast.KindCodeSpan:
+			return ast.WalkSkipChildren, nil
+		case ast.KindRawHTML:
+			segments := n.(*ast.RawHTML).Segments
+			if segments.Len() == 0 {
+				return ast.WalkContinue, nil
+			}
+			start := segments.At(0).Start
+			if len(spans) > 0 && start < spans[len(spans)-1].End {
+				return ast.WalkContinue, nil // Part of the previous span, e.g. its "<rt>"
+			}
+			if m := rubyPattern.FindSubmatchIndex(content[start:]); m != nil {
+				spans = append(spans, rubySpan{
+					Start:   start,
+					End:     start + m[1],
+					Word:    string(content[start+m[2] : start+m[3]]),
+					Reading: string(content[start+m[4] : start+m[5]]),
+				})
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return spans
+}
+
+// findRubyElements returns every inline ruby element in the parsed document, from its
+// opening tag to its closing tag, in document order. Unlike findRubySpans it also finds
+// markup not generated by rubi, e.g. with a class attribute or <rb> elements, whose text
+// must not be converted either. Only Start and End of the returned spans are set.
+func findRubyElements(document ast.Node, content []byte) []rubySpan {
+	var elements []rubySpan
+	var open ast.Node // Opening tag of the element being read, if any
+	start := 0
+	ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindCodeBlock, ast.KindFencedCodeBlock, ast.KindHTMLBlock, ast.KindCodeSpan:
+			return ast.WalkSkipChildren, nil
+		case ast.KindRawHTML:
+			segments := n.(*ast.RawHTML).Segments
+			if segments.Len() == 0 {
+				return ast.WalkContinue, nil
+			}
+			if open != nil && open.Parent() != n.Parent() {
+				open = nil // An element left open does not reach into the next paragraph
+			}
+			segment := segments.At(segments.Len() - 1)
+			tag := content[segments.At(0).Start:segment.Stop]
+			switch {
+			case open == nil && rubyOpenTag.Match(tag):
+				open, start = n, segments.At(0).Start
+			case open != nil && rubyCloseTag.Match(tag):
+				elements = append(elements, rubySpan{Start: start, End: segment.Stop})
+				open = nil
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return elements
+}
+
+// rubySpanIndex returns the index of the span in spans that contains offset, or -1 if there is none.
+func rubySpanIndex(spans []rubySpan, offset int) int {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i].End > offset })
+	if i < len(spans) && spans[i].Start <= offset {
+		return i
+	}
+	return -1
+}
+
+// StripRuby removes the ruby markup generated by rubi from content, leaving the base text,
+// as well as the generated glossary and "読み方の出典" sections.
+// With restoreMarkers, each span is turned back into a manual-mode marker instead:
+// "word:rubi", "{some words}:rubi" for text that is not a single token, and
+// "word:rubi(reading)" when the reading differs from termMap, so that re-running
+// rubi reproduces the same output.
+func StripRuby(content []byte, restoreMarkers bool, termMap map[string]Term) (*Result, error) {
+	source := content
+	if fm := findFrontMatter(content); fm != nil {
+		source = fm.mask(content) // Front matter is left alone
+	}
+	document := newMarkdown().Parser().Parse(text.NewReader(source))
+
+	index := newTermIndex(termMap)
+	result := &Result{Content: content}
+	for _, span := range findRubySpans(document, content) {
+		word, reading := html.UnescapeString(span.Word), html.UnescapeString(span.Reading)
+		term, found := index.lookup(word)
+		replacement := span.Word
+		if restoreMarkers {
+			replacement = rubiMarkerFor(word)
+			if !found || term.Yomi != reading {
+				replacement += "(" + reading + ")"
+			}
+		}
+		result.Patches = append(result.Patches, Patch{Start: span.Start, End: span.End, NewText: []byte(replacement)})
+		result.Occurrences = append(result.Occurrences, Occurrence{Start: span.Start, End: span.End, Term: word, Yomi: reading, Ref: term.Ref, Mode: ModeStrip})
+	}
+
+	// Remove the generated sections, along with the blank line before them. A glossary
+	// followed by more text was placed at a marker, which is put back for the next run.
+	comments := findCommentLines(document, content)
+	for _, name := range generatedSections {
+		if start, end, ok := findSection(comments, content, name); ok {
+			if name == glossarySection && len(bytes.TrimSpace(content[end:])) > 0 {
+				result.Patches = append(result.Patches, Patch{Start: start, End: end, NewText: []byte(glossaryMarker + "\n")})
+				continue
+			}
+			if start >= 2 && content[start-1] == '\n' && content[start-2] == '\n' {
+				start--
+			}
+			result.Patches = append(result.Patches, Patch{Start: start, End: end})
+		}
+	}
+
+	if len(result.Patches) == 0 {
+		return result, nil
+	}
+	newContent, err := ApplyPatches(content, result.Patches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to strip ruby markup: %w", err)
+	}
+	result.Content = newContent
+	return result, nil
+}
+
+// rubiMarkerFor returns the manual-mode marker for word.
+func rubiMarkerFor(word string) string {
+	if word != "" && lastTokenStart(word) == 0 {
+		return word + rubiSuffix
+	}
+	return "{" + word + "}" + rubiSuffix
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// --- Test StripRuby ---
+
+func TestStripRuby(t *testing.T) {
+	termMap := map[string]Term{
+		"Vite":       {Term: "Vite", Yomi: "ヴィート"},
+		"Go":         {Term: "Go", Yomi: "ゴー"},
+		"Vue Router": {Term: "Vue Router", Yomi: "ビュールーター"},
+	}
+
+	tests := []struct {
+		name           string
+		input          string
+		restoreMarkers bool
+		expected       string
+	}{
+		{
+			name:     "plain text",
+			input:    "これは<ruby>Vite<rt>ヴィート</rt></ruby>です。",
+			expected: "これはViteです。",
+		},
+		{
+			name:     "multiple spans",
+			input:    "<ruby>Go<rt>ゴー</rt></ruby> and <ruby>Vite<rt>ヴィート</rt></ruby>\n",
+			expected: "Go and Vite\n",
+		},
+		{
+			name:     "ruby with rp parentheses",
+			input:    "<ruby>Vite<rp>(</rp><rt>ヴィート</rt><rp>)</rp></ruby>",
+			expected: "Vite",
+		},
+		{
+			name:     "code is left alone",
+			input:    "`<ruby>Go<rt>ゴー</rt></ruby>`\n\n```\n<ruby>Go<rt>ゴー</rt></ruby>\n```\n",
+			expected: "`<ruby>Go<rt>ゴー</rt></ruby>`\n\n```\n<ruby>Go<rt>ゴー</rt></ruby>\n```\n",
+		},
+		{
+			name:     "hand-written ruby with attributes is left alone",
+			input:    `<ruby class="x">Go<rt>ゴー</rt></ruby>`,
+			expected: `<ruby class="x">Go<rt>ゴー</rt></ruby>`,
+		},
+		{
+			name:     "escaped text is kept as written",
+			input:    "<ruby>A&amp;B<rt>えーあんどびー</rt></ruby>",
+			expected: "A&amp;B",
+		},
+		{
+			name:     "generated sources section is removed",
+			input:    "<ruby>Vite<rt>ヴィート</rt></ruby>\n\n<!-- rubi:refs:start -->\n## 読み方の出典\n\n- Vite（ヴィート）: <https://ja.vitejs.dev/>\n\n<!-- rubi:refs:end -->\n",
+			expected: "Vite\n",
+		},
+		{
+			name:           "restore markers",
+			input:          "これは<ruby>Vite<rt>ヴィート</rt></ruby>です。",
+			restoreMarkers: true,
+			expected:       "これはVite:rubiです。",
+		},
+		{
+			name:           "restore braced marker",
+			input:          "<ruby>Vue Router<rt>ビュールーター</rt></ruby>",
+			restoreMarkers: true,
+			expected:       "{Vue Router}:rubi",
+		},
+		{
+			name:           "restore inline reading for unknown term",
+			input:          "<ruby>Nuxt<rt>ナクスト</rt></ruby>",
+			restoreMarkers: true,
+			expected:       "Nuxt:rubi(ナクスト)",
+		},
+		{
+			name:           "restore inline reading that differs from the dictionary",
+			input:          "<ruby>Go<rt>ごー</rt></ruby>",
+			restoreMarkers: true,
+			expected:       "Go:rubi(ごー)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := StripRuby([]byte(tt.input), tt.restoreMarkers, termMap)
+			if err != nil {
+				t.Fatalf("StripRuby() error = %v", err)
+			}
+			if string(result.Content) != tt.expected {
+				t.Errorf("StripRuby() got = %q, want %q", string(result.Content), tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripRuby_RoundTrip(t *testing.T) {
+	termMap := map[string]Term{
+		"Vite":       {Term: "Vite", Yomi: "ヴィート"},
+		"Vue Router": {Term: "Vue Router", Yomi: "ビュールーター"},
+	}
+	input := "Vite:rubiと{Vue Router}:rubiとNuxt:rubi(ナクスト)を使う。\n"
+
+	converted, err := NewProcessor(termMap).Process([]byte(input), Options{Quiet: true})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	stripped, err := StripRuby(converted.Content, true, termMap)
+	if err != nil {
+		t.Fatalf("StripRuby() error = %v", err)
+	}
+	if string(stripped.Content) != input {
+		t.Errorf("StripRuby() got = %q, want %q", string(stripped.Content), input)
+	}
+}
+
+func TestStripRuby_GlossaryRoundTrip(t *testing.T) {
+	processor := NewProcessor(map[string]Term{"Vite": {Term: "Vite", Yomi: "ヴィート"}})
+	opts := Options{Scan: true, Glossary: true, Quiet: true}
+
+	for _, input := range []string{
+		"# Doc\n\n<!-- rubi:glossary -->\n\nVite and Go.\n",
+		"# Doc\n\nVite and Go.\n",
+	} {
+		converted, err := processor.Process([]byte(input), opts)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		stripped, err := StripRuby(converted.Content, false, nil)
+		if err != nil {
+			t.Fatalf("StripRuby() error = %v", err)
+		}
+		if string(stripped.Content) != input {
+			t.Errorf("StripRuby() got = %q, want %q", stripped.Content, input)
+		}
+		again, err := processor.Process(stripped.Content, opts)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if string(again.Content) != string(converted.Content) {
+			t.Errorf("Process() after strip got = %q, want %q", again.Content, converted.Content)
+		}
+	}
+}
+
+// --- Test handleStripCommand ---
+
+func TestHandleStripCommand(t *testing.T) {
+	dir := t.TempDir()
+	dictFile := filepath.Join(dir, "dict.yaml")
+	writeTestFile(t, dictFile, testDictYAML)
+	file := filepath.Join(dir, "post.md")
+	writeTestFile(t, file, "<ruby>Vite<rt>ヴィート</rt></ruby>\n")
+
+	cfg := &Config{DictPaths: []string{dictFile}, Write: true, Jobs: 1, Inputs: []string{file}}
+	captureStdout(t, func() {
+		if err := handleStripCommand(cfg, true); err != nil {
+			t.Fatalf("handleStripCommand() error = %v", err)
+		}
+	})
+
+	got, _ := os.ReadFile(file)
+	if string(got) != "Vite:rubi\n" {
+		t.Errorf("handleStripCommand() wrote %q, want %q", string(got), "Vite:rubi\n")
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Scopes accepted by --first-only-scope. They decide where --first-only starts
+// converting the first occurrence of each term again.
+const (
+	scopePage       = "page"       // Once per document
+	scopeSection    = "section"    // Once per section, "section:N" for headings of level N or higher (default 2)
+	scopeParagraphs = "paragraphs" // Once every N paragraphs (or list items), written as "paragraphs:N"
+)
+
+// defaultSectionLevel is the heading level that starts a new "section" scope.
+const defaultSectionLevel = 2
+
+// scopeRule is a parsed --first-only-scope value.
+type scopeRule struct {
+	Kind string
+	N    int // Heading level for scopeSection, number of paragraphs for scopeParagraphs
+}
+
+// parseFirstOnlyScope parses a --first-only-scope value. An empty value selects scopePage.
+func parseFirstOnlyScope(value string) (scopeRule, error) {
+	kind, arg, hasArg := strings.Cut(value, ":")
+	invalid := fmt.Errorf("invalid first-only scope '%s' (expected page, section, section:LEVEL or paragraphs:N)", value)
+
+	switch kind {
+	case "", scopePage:
+		if hasArg {
+			return scopeRule{}, invalid
+		}
+		return scopeRule{Kind: scopePage}, nil
+	case scopeSection:
+		if !hasArg {
+			return scopeRule{Kind: scopeSection, N: defaultSectionLevel}, nil
+		}
+		level, err := strconv.Atoi(arg)
+		if err != nil || level < 1 || level > 6 {
+			return scopeRule{}, invalid
+		}
+		return scopeRule{Kind: scopeSection, N: level}, nil
+	case scopeParagraphs:
+		n, err := strconv.Atoi(arg)
+		if !hasArg || err != nil || n < 1 {
+			return scopeRule{}, invalid
+		}
+		return scopeRule{Kind: scopeParagraphs, N: n}, nil
+	}
+	return scopeRule{}, invalid
+}
+
+// scopeTracker finds the nodes at which a new first-only scope begins.
+type scopeTracker struct {
+	scope      scopeRule
+	paragraphs int // Paragraphs seen so far
+}
+
+// starts reports whether a new scope begins at n. It must be called for every node on entering, in document order.
+func (t *scopeTracker) starts(n ast.Node) bool {
+	switch t.scope.Kind {
+	case scopeSection:
+		heading, ok := n.(*ast.Heading)
+		return ok && heading.Level <= t.scope.N
+	case scopeParagraphs:
+		if n.Kind() != ast.KindParagraph && n.Kind() != ast.KindTextBlock { // Items of tight lists are text blocks
+			return false
+		}
+		t.paragraphs++
+		return t.paragraphs > 1 && (t.paragraphs-1)%t.scope.N == 0
+	}
+	return false
+}
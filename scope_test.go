@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+// --- Test first-only scopes ---
+
+func TestParseFirstOnlyScope(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    scopeRule
+		wantErr bool
+	}{
+		{value: "", want: scopeRule{Kind: scopePage}},
+		{value: "page", want: scopeRule{Kind: scopePage}},
+		{value: "section", want: scopeRule{Kind: scopeSection, N: 2}},
+		{value: "section:3", want: scopeRule{Kind: scopeSection, N: 3}},
+		{value: "paragraphs:5", want: scopeRule{Kind: scopeParagraphs, N: 5}},
+		{value: "section:7", wantErr: true},
+		{value: "paragraphs", wantErr: true},
+		{value: "paragraphs:0", wantErr: true},
+		{value: "page:1", wantErr: true},
+		{value: "chapter", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseFirstOnlyScope(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFirstOnlyScope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseFirstOnlyScope() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcess_FirstOnlyScope(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+	const g = "<ruby>Go<rt>ゴー</rt></ruby>"
+
+	tests := []struct {
+		name       string
+		scope      string
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "page",
+			scope:      "page",
+			input:      "# Go\n\nGo\n\n## A\n\nGo\n\n### B\n\nGo\n",
+			wantOutput: "# " + g + "\n\nGo\n\n## A\n\nGo\n\n### B\n\nGo\n",
+		},
+		{
+			name:       "section",
+			scope:      "section",
+			input:      "# Go\n\nGo\n\n## A\n\nGo\n\n### B\n\nGo\n\n## C\n\nGo Go\n",
+			wantOutput: "# " + g + "\n\nGo\n\n## A\n\n" + g + "\n\n### B\n\nGo\n\n## C\n\n" + g + " Go\n",
+		},
+		{
+			name:       "section:3",
+			scope:      "section:3",
+			input:      "## A\n\nGo\n\n### B\n\nGo\n",
+			wantOutput: "## A\n\n" + g + "\n\n### B\n\n" + g + "\n",
+		},
+		{
+			name:       "paragraphs:2",
+			scope:      "paragraphs:2",
+			input:      "Go\n\nGo\n\nGo\n\n- Go\n- Go\n",
+			wantOutput: g + "\n\nGo\n\n" + g + "\n\n- Go\n- " + g + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := processor.Process([]byte(tt.input), Options{Scan: true, FirstOnly: true, FirstOnlyScope: tt.scope, Quiet: true})
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+		})
+	}
+}
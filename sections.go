@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Sections that rubi generates and keeps up to date in a document. A section is
+// wrapped in "<!-- rubi:NAME:start -->" and "<!-- rubi:NAME:end -->" comments so
+// that re-running rubi replaces it instead of adding another copy.
+const (
+	glossarySection = "glossary" // "用語と読み方" table added by --glossary
+	refsSection     = "refs"     // "読み方の出典" list added by --ref footnote
+)
+
+// generatedSections lists every section rubi generates.
+var generatedSections = []string{glossarySection, refsSection}
+
+// glossaryMarker marks where the glossary is inserted. Without it, the glossary is appended to the document.
+const glossaryMarker = "<!-- rubi:glossary -->"
+
+// sectionMarkers returns the comments that wrap the generated section called name.
+func sectionMarkers(name string) (string, string) {
+	return fmt.Sprintf("<!-- rubi:%s:start -->", name), fmt.Sprintf("<!-- rubi:%s:end -->", name)
+}
+
+// findCommentLines returns the offset of every "<!-- rubi:... -->" comment that is
+// on a line of its own, keyed by the comment. Only HTML blocks are considered, so
+// comments shown in code blocks or code spans are ignored.
+func findCommentLines(document ast.Node, content []byte) map[string]int {
+	comments := make(map[string]int)
+	ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != ast.KindHTMLBlock {
+			return ast.WalkContinue, nil
+		}
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			segment := lines.At(i)
+			value := string(segment.Value(content))
+			line := strings.TrimSpace(value)
+			if _, seen := comments[line]; !seen && strings.HasPrefix(line, "<!-- rubi:") && strings.HasSuffix(line, "-->") {
+				comments[line] = segment.Start + len(value) - len(strings.TrimLeft(value, " \t"))
+			}
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return comments
+}
+
+// findSection returns the byte range of the generated section called name, from its
+// start marker to just past the line of its end marker. comments is the result of findCommentLines.
+func findSection(comments map[string]int, content []byte, name string) (int, int, bool) {
+	startMarker, endMarker := sectionMarkers(name)
+	start, ok := comments[startMarker]
+	if !ok {
+		return 0, 0, false
+	}
+	end, ok := comments[endMarker]
+	if !ok || end < start {
+		return 0, 0, false
+	}
+	end += len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
+
+// wrapSection wraps body in the markers of the section called name.
+func wrapSection(name, body string) string {
+	startMarker, endMarker := sectionMarkers(name)
+	return startMarker + "\n" + body + "\n" + endMarker + "\n"
+}
+
+// renderRefsSection renders the "読み方の出典" section listing the reading source of
+// every term in terms. It returns "" if no term has a source.
+func renderRefsSection(terms []Term) string {
+	var b strings.Builder
+	for _, term := range terms {
+		if term.Ref == "" {
+			continue
+		}
+		ref := term.Ref
+		if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+			ref = "<" + ref + ">" // Autolink
+		}
+		fmt.Fprintf(&b, "- %s（%s）: %s\n", term.Term, term.Yomi, ref)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return wrapSection(refsSection, "## 読み方の出典\n\n"+b.String())
+}
+
+// renderGlossarySection renders the "用語と読み方" table listing terms with their
+// readings and sources, and with alternates also their alternate readings.
+// It returns "" if terms is empty.
+func renderGlossarySection(terms []Term, alternates bool) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	if alternates {
+		b.WriteString("## 用語と読み方\n\n| 用語 | 読み方 | 別の読み方 | 出典 |\n| :--- | :--- | :--- | :--- |\n")
+	} else {
+		b.WriteString("## 用語と読み方\n\n| 用語 | 読み方 | 出典 |\n| :--- | :--- | :--- |\n")
+	}
+	for _, term := range terms {
+		ref := term.Ref
+		if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+			ref = "<" + ref + ">" // Autolink
+		}
+		if alternates {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", escapeTableCell(term.Term), escapeTableCell(term.Yomi), escapeTableCell(formatReadings(term.Alternates())), escapeTableCell(ref))
+		} else {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", escapeTableCell(term.Term), escapeTableCell(term.Yomi), escapeTableCell(ref))
+		}
+	}
+	return wrapSection(glossarySection, b.String())
+}
+
+// escapeTableCell escapes the pipes in s so it can be used in a Markdown table cell.
+func escapeTableCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// --- Test generated sections ---
+
+func TestProcess_Glossary(t *testing.T) {
+	processor := NewProcessor(createTestTermMap())
+	table := "<!-- rubi:glossary:start -->\n## 用語と読み方\n\n| 用語 | 読み方 | 出典 |\n| :--- | :--- | :--- |\n" +
+		"| Go | ゴー |  |\n" +
+		"| Vite | ヴィート | <https://ja.vitejs.dev/> |\n" +
+		"\n<!-- rubi:glossary:end -->\n"
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "appended in manual mode without converting",
+			opts:       Options{Glossary: true},
+			input:      "Go and Vite\n",
+			wantOutput: "Go and Vite\n\n" + table,
+		},
+		{
+			name:       "terms in existing ruby and markers are listed",
+			opts:       Options{Glossary: true},
+			input:      "<ruby>Go<rt>ゴー</rt></ruby> and Vite:rubi\n",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby> and <ruby>Vite<rt>ヴィート</rt></ruby>\n\n" + table,
+		},
+		{
+			name:       "inserted at the marker",
+			opts:       Options{Glossary: true},
+			input:      "# Title\n\n<!-- rubi:glossary -->\n\nGo and Vite\n",
+			wantOutput: "# Title\n\n" + table + "\nGo and Vite\n",
+		},
+		{
+			name:       "terms in code are not listed",
+			opts:       Options{Glossary: true},
+			input:      "Go and `Vite`\n\n```\ngRPC\n```\n\nVite\n",
+			wantOutput: "Go and `Vite`\n\n```\ngRPC\n```\n\nVite\n\n" + table,
+		},
+		{
+			name:       "marker in a code block is ignored",
+			opts:       Options{Glossary: true},
+			input:      "```\n<!-- rubi:glossary -->\n```\n",
+			wantOutput: "```\n<!-- rubi:glossary -->\n```\n",
+		},
+		{
+			name:       "existing table is updated in place",
+			opts:       Options{Glossary: true, Scan: true},
+			input:      "Go and Vite\n\n<!-- rubi:glossary:start -->\n## 用語と読み方\n\n| Go | ゴー |  |\n\n<!-- rubi:glossary:end -->\n\nThe end.\n",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby> and <ruby>Vite<rt>ヴィート</rt></ruby>\n\n" + table + "\nThe end.\n",
+		},
+		{
+			name:       "empty table turns back into the marker",
+			opts:       Options{Glossary: true},
+			input:      "Nothing here\n\n" + table,
+			wantOutput: "Nothing here\n\n<!-- rubi:glossary -->\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if string(result.Content) != tt.wantOutput {
+				t.Errorf("Process() got = %q, want %q", result.Content, tt.wantOutput)
+			}
+
+			// Running again must not change anything
+			again, err := processor.Process(result.Content, tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if len(again.Patches) > 0 {
+				t.Errorf("Process() is not idempotent: second run = %q", again.Content)
+			}
+		})
+	}
+}
+
+func TestRenderGlossarySection_EscapesPipes(t *testing.T) {
+	got := renderGlossarySection([]Term{{Term: "A|B", Yomi: "えーびー"}}, false)
+	if !strings.Contains(got, `| A\|B | えーびー |  |`) {
+		t.Errorf("renderGlossarySection() = %q, want escaped pipe", got)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build ignore
+
 package main
 
 import (
@@ -9,11 +11,23 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Term は辞書の1つのエントリを表す
+// Term は辞書の1つのエントリを表す（並べ替えで項目が失われないよう、すべての項目を持つ）
 type Term struct {
-	Term string `yaml:"term"`
-	Yomi string `yaml:"yomi"`
-	Ref  string `yaml:"ref,omitempty"`
+	Term          string    `yaml:"term"`
+	Yomi          string    `yaml:"yomi,omitempty"`
+	Ref           string    `yaml:"ref,omitempty"`
+	Priority      int       `yaml:"priority,omitempty"`
+	Aliases       []string  `yaml:"aliases,omitempty"`
+	CaseSensitive *bool     `yaml:"case_sensitive,omitempty"`
+	Tags          []string  `yaml:"tags,omitempty"`
+	Readings      []Reading `yaml:"readings,omitempty"`
+}
+
+// Reading は用語の読み方の候補の1つを表す
+type Reading struct {
+	Yomi      string `yaml:"yomi"`
+	Preferred bool   `yaml:"preferred,omitempty"`
+	Note      string `yaml:"note,omitempty"`
 }
 
 // Dictionary は辞書ファイル全体の構造
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tagFilter selects the dictionary terms converted in scan mode by their tags,
+// as given with --only-tags and --exclude-tags.
+type tagFilter struct {
+	only    map[string]bool // If not empty, a term needs one of these tags
+	exclude map[string]bool // A term with any of these tags is never selected
+}
+
+// newTagFilter returns a filter for the given tags. Without any tags every term is selected.
+func newTagFilter(only, exclude []string) tagFilter {
+	f := tagFilter{only: make(map[string]bool), exclude: make(map[string]bool)}
+	for _, tag := range only {
+		f.only[tag] = true
+	}
+	for _, tag := range exclude {
+		f.exclude[tag] = true
+	}
+	return f
+}
+
+// selects reports whether the term passes the filter.
+func (f tagFilter) selects(term Term) bool {
+	included := len(f.only) == 0
+	for _, tag := range term.Tags {
+		if f.exclude[tag] {
+			return false
+		}
+		included = included || f.only[tag]
+	}
+	return included
+}
+
+// validateTags checks that every tag is used by at least one term of the dictionary,
+// so that a misspelled tag does not silently select nothing.
+func validateTags(termMap map[string]Term, tags ...[]string) error {
+	known := make(map[string]bool)
+	for _, term := range termMap {
+		for _, tag := range term.Tags {
+			known[tag] = true
+		}
+	}
+	for _, list := range tags {
+		for _, tag := range list {
+			if !known[tag] {
+				return fmt.Errorf("unknown tag '%s' (no term in the dictionary has it; known tags: %s)", tag, joinSorted(known))
+			}
+		}
+	}
+	return nil
+}
+
+// joinSorted returns the keys of set in sorted order, separated by commas.
+func joinSorted(set map[string]bool) string {
+	if len(set) == 0 {
+		return "none"
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
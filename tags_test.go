@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTagFilter(t *testing.T) {
+	vite := Term{Term: "Vite", Tags: []string{"frontend", "build"}}
+	api := Term{Term: "API", Tags: []string{"acronym"}}
+	untagged := Term{Term: "Go"}
+
+	tests := []struct {
+		name    string
+		only    []string
+		exclude []string
+		want    map[string]bool
+	}{
+		{name: "no filter", want: map[string]bool{"Vite": true, "API": true, "Go": true}},
+		{name: "only", only: []string{"frontend", "infra"}, want: map[string]bool{"Vite": true}},
+		{name: "exclude", exclude: []string{"acronym"}, want: map[string]bool{"Vite": true, "Go": true}},
+		{name: "exclude wins over only", only: []string{"frontend"}, exclude: []string{"build"}, want: map[string]bool{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTagFilter(tt.only, tt.exclude)
+			for _, term := range []Term{vite, api, untagged} {
+				if got := f.selects(term); got != tt.want[term.Term] {
+					t.Errorf("selects(%s) = %v, want %v", term.Term, got, tt.want[term.Term])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	termMap := map[string]Term{
+		"Vite": {Term: "Vite", Tags: []string{"frontend"}},
+		"API":  {Term: "API", Tags: []string{"acronym"}},
+	}
+	if err := validateTags(termMap, []string{"frontend"}, []string{"acronym"}); err != nil {
+		t.Errorf("validateTags() unexpected error: %v", err)
+	}
+	err := validateTags(termMap, nil, []string{"acronyms"})
+	if want := "unknown tag 'acronyms' (no term in the dictionary has it; known tags: acronym, frontend)"; err == nil || err.Error() != want {
+		t.Errorf("validateTags() error = %v, want %q", err, want)
+	}
+}
+
+func TestProcess_Tags(t *testing.T) {
+	processor := NewProcessor(map[string]Term{
+		"Go":         {Term: "Go", Yomi: "ゴー", Tags: []string{"language"}},
+		"Go modules": {Term: "Go modules", Yomi: "ゴーモジュールズ", Tags: []string{"tooling"}},
+		"API":        {Term: "API", Yomi: "エーピーアイ", Tags: []string{"acronym"}},
+	})
+
+	tests := []struct {
+		name       string
+		opts       Options
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "only tags",
+			opts:       Options{Scan: true, OnlyTags: []string{"acronym"}},
+			input:      "Go and API",
+			wantOutput: "Go and <ruby>API<rt>エーピーアイ</rt></ruby>",
+		},
+		{
+			name:       "exclude tags",
+			opts:       Options{Scan: true, ExcludeTags: []string{"acronym"}},
+			input:      "Go and API",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby> and API",
+		},
+		{
+			name:       "an excluded longer term leaves the shorter one",
+			opts:       Options{Scan: true, ExcludeTags: []string{"tooling"}},
+			input:      "Go modules",
+			wantOutput: "<ruby>Go<rt>ゴー</rt></ruby> modules",
+		},
+		{
+			name:       "manual markers ignore tags",
+			opts:       Options{OnlyTags: []string{"language"}},
+			input:      "API:rubi",
+			wantOutput: "<ruby>API<rt>エーピーアイ</rt></ruby>",
+		},
+		{
+			name:       "front matter overrides the tags",
+			opts:       Options{Scan: true, OnlyTags: []string{"acronym"}},
+			input:      "---\nrubi:\n  onlyTags: [language]\n---\nGo and API",
+			wantOutput: "---\nrubi:\n  onlyTags: [language]\n---\n<ruby>Go<rt>ゴー</rt></ruby> and API",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Quiet = true
+			result, err := processor.Process([]byte(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if got := string(result.Content); got != tt.wantOutput {
+				t.Errorf("Process() = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestHandleMainCommand_TagErrors(t *testing.T) {
+	dictFile := createTempDictFile(t, "terms:\n  - term: Vite\n    yomi: ヴィート\n    tags: [frontend]\n")
+	defer os.Remove(dictFile)
+	doc := filepath.Join(t.TempDir(), "doc.md")
+	writeTestFile(t, doc, "Vite\n")
+
+	tests := []struct {
+		name        string
+		cfg         *Config
+		errContains string
+	}{
+		{
+			name:        "manual mode",
+			cfg:         &Config{DictPaths: []string{dictFile}, OnlyTags: []string{"frontend"}, Inputs: []string{doc}},
+			errContains: "only valid in -s (scan) mode",
+		},
+		{
+			name:        "unknown tag",
+			cfg:         &Config{DictPaths: []string{dictFile}, Scan: true, ExcludeTags: []string{"front-end"}, Inputs: []string{doc}},
+			errContains: "unknown tag 'front-end'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := handleMainCommand(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("handleMainCommand() error = %v, want error containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// charClass groups runes for word boundary detection. Two adjacent runes belong
+// to the same word only when they share a class other than classOther, so a
+// change of script (e.g. Latin to kana in "Goは") is always a boundary.
+type charClass int
+
+const (
+	classOther    charClass = iota // Spaces, punctuation and symbols
+	classWord                      // Letters and digits of alphabetic scripts, and underscore
+	classHan                       // Kanji
+	classHiragana                  // Hiragana
+	classKatakana                  // Katakana, including the prolonged sound mark
+	classHangul                    // Hangul
+)
+
+// classOf returns the character class of r.
+func classOf(r rune) charClass {
+	switch {
+	case r == '_':
+		return classWord
+	case r == 'ー' || r == 'ｰ': // The prolonged sound mark belongs to the Common script
+		return classKatakana
+	case r == '々' || r == '〆':
+		return classHan
+	case unicode.Is(unicode.Han, r):
+		return classHan
+	case unicode.Is(unicode.Hiragana, r):
+		return classHiragana
+	case unicode.Is(unicode.Katakana, r):
+		return classKatakana
+	case unicode.Is(unicode.Hangul, r):
+		return classHangul
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return classWord
+	default:
+		return classOther
+	}
+}
+
+// isTokenBoundary reports whether byte offset i in s separates two tokens.
+// The start and end of s are always boundaries. Inside s, offset i is a boundary
+// unless the runes on both sides belong to the same word class, so punctuation,
+// spaces and script changes (Latin, kanji, hiragana, katakana) all delimit tokens.
+// This lets dictionary terms contain dots, hyphens, plus signs, spaces and CJK
+// characters while still matching only whole tokens at their edges.
+func isTokenBoundary(s string, i int) bool {
+	if i <= 0 || i >= len(s) {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[i:])
+	cb, ca := classOf(before), classOf(after)
+	return cb == classOther || ca == classOther || cb != ca
+}
+
+// lastTokenStart returns the byte offset where the last token of s begins,
+// or len(s) if s does not end with a word character.
+func lastTokenStart(s string) int {
+	end := len(s)
+	r, size := utf8.DecodeLastRuneInString(s)
+	class := classOf(r)
+	if size == 0 || class == classOther {
+		return end
+	}
+	start := end - size
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:start])
+		if classOf(r) != class {
+			break
+		}
+		start -= size
+	}
+	return start
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+// --- Test isTokenBoundary ---
+
+func TestIsTokenBoundary(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		i    int
+		want bool
+	}{
+		{name: "start of text", s: "Go", i: 0, want: true},
+		{name: "end of text", s: "Go", i: 2, want: true},
+		{name: "inside latin word", s: "golang", i: 2, want: false},
+		{name: "latin to space", s: "Go is", i: 2, want: true},
+		{name: "latin to hiragana", s: "Goは", i: 2, want: true},
+		{name: "latin to kanji", s: "Go言語", i: 2, want: true},
+		{name: "inside kanji run", s: "言語", i: 3, want: false},
+		{name: "kanji to hiragana", s: "言語は", i: 6, want: true},
+		{name: "katakana with prolonged sound mark", s: "ヴィート", i: 9, want: false},
+		{name: "katakana to hiragana", s: "ヴィートは", i: 12, want: true},
+		{name: "latin to punctuation", s: "Node.js", i: 4, want: true},
+		{name: "digit inside word", s: "k8s", i: 1, want: false},
+		{name: "underscore inside word", s: "foo_bar", i: 3, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTokenBoundary(tt.s, tt.i); got != tt.want {
+				t.Errorf("isTokenBoundary(%q, %d) = %v, want %v", tt.s, tt.i, got, tt.want)
+			}
+		})
+	}
+}
+
+// --- Test lastTokenStart ---
+
+func TestLastTokenStart(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{s: "Hello Vite", want: 6},
+		{s: "これはGo", want: 9},
+		{s: "Go言語", want: 2},
+		{s: "ends with space ", want: 16},
+		{s: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := lastTokenStart(tt.s); got != tt.want {
+				t.Errorf("lastTokenStart(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}